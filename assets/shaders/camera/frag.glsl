@@ -2,6 +2,7 @@
 
 in vec3 FragPos;
 in vec3 Normal;
+in vec4 Tint;
 in float FaceVisible;
 
 out vec4 FragColor;
@@ -50,8 +51,9 @@ void main() {
         result = vec3(0.0); // or: result = ambientStrength * color.rgb;
     }
 
-    // Apply material color and occlusion
-    result = result * color.rgb;
+    // Apply material color, instance tint and occlusion
+    vec4 tinted = color * Tint;
+    result = result * tinted.rgb;
     result = mix(result, result * occlusionStrength, 0.3); // Blend occlusion effect
-    FragColor = vec4(result, color.a);
+    FragColor = vec4(result, tinted.a);
 }
@@ -3,6 +3,12 @@
 layout (location = 0) in vec3 aPos;
 layout (location = 2) in vec3 aNormal;
 
+// Per-instance attributes of instanced draws, the model matrix takes
+// locations 3 to 6
+layout (location = 3) in mat4 aModel;
+layout (location = 7) in vec4 aTint;
+
+uniform bool instanced;
 uniform mat4 model;
 uniform mat4 view;
 uniform mat4 projection;
@@ -10,11 +16,17 @@ uniform vec3 viewPos;
 
 out vec3 FragPos;
 out vec3 Normal;
+out vec4 Tint;
 out float FaceVisible;
 
 void main() {
-    FragPos = vec3(model * vec4(aPos, 1.0));
-    Normal = mat3(transpose(inverse(model))) * aNormal;
+    // Instanced draws take the transform and tint of every instance from the
+    // instance buffer, other draws use the model uniform
+    mat4 modelMatrix = instanced ? aModel : model;
+    Tint = instanced ? aTint : vec4(1.0);
+
+    FragPos = vec3(modelMatrix * vec4(aPos, 1.0));
+    Normal = mat3(transpose(inverse(modelMatrix))) * aNormal;
     
     // Calculate face normal in world space
     vec3 faceNormal = normalize(Normal);
@@ -0,0 +1,10 @@
+#version 410 core
+
+in vec3 LineColor;
+
+out vec4 FragColor;
+
+void main() {
+    // Debug lines are unlit so they keep their color from every angle
+    FragColor = vec4(LineColor, 1.0);
+}
@@ -0,0 +1,14 @@
+#version 410 core
+
+layout (location = 0) in vec3 aPos;
+layout (location = 1) in vec3 aColor;
+
+uniform mat4 view;
+uniform mat4 projection;
+
+out vec3 LineColor;
+
+void main() {
+    LineColor = aColor;
+    gl_Position = projection * view * vec4(aPos, 1.0);
+}
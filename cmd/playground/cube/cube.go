@@ -2,13 +2,19 @@ package cube
 
 import (
 	"otto"
+	"otto/system/physics"
 
 	"github.com/anthdm/hollywood/actor"
 	"github.com/go-gl/mathgl/mgl64"
 )
 
+// hardImpactImpulse is the impulse above which a cube gets knocked back
+const hardImpactImpulse = 14.0
+
 type Cube struct {
 	*otto.Entity
+
+	physicsPID *actor.PID
 }
 
 var _ actor.Receiver = (*Cube)(nil)
@@ -19,7 +25,7 @@ func NewCube(physicsPID, rendererPID, inputPID *actor.PID) actor.Producer {
 		entity.ModelName = "cube"
 		entity.EntityType = "cube"
 		entity.Position = mgl64.Vec3{0, 0, 2} // Position the cube in front of the camera
-		return &Cube{Entity: entity}
+		return &Cube{Entity: entity, physicsPID: physicsPID}
 	}
 }
 
@@ -30,6 +36,22 @@ func NewCubeWithPosition(physicsPID, rendererPID, inputPID *actor.PID, position
 		entity.EntityType = "cube"
 		entity.Position = position
 		entity.Scale = mgl64.Vec3{1, 1, 1}
-		return &Cube{Entity: entity}
+		return &Cube{Entity: entity, physicsPID: physicsPID}
+	}
+}
+
+// Receive implements actor.Receiver.
+func (c *Cube) Receive(ctx *actor.Context) {
+	switch msg := ctx.Message().(type) {
+	case physics.EventCollisionEnter:
+		// Hard hits knock the cube away from whatever hit it
+		if msg.Impulse > hardImpactImpulse && c.physicsPID != nil {
+			ctx.Send(c.physicsPID, physics.EventApplyImpulse{
+				PID:     ctx.PID(),
+				Impulse: msg.Normal.Mul(-msg.Impulse * 0.5),
+			})
+		}
+	default:
+		c.Entity.Receive(ctx)
 	}
 }
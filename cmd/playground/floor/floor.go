@@ -1,6 +1,7 @@
 package floor
 
 import (
+	"math"
 	"otto"
 	"otto/system/physics"
 	"otto/system/renderer"
@@ -23,10 +24,13 @@ func New(renderPID, physicsPID *actor.PID) actor.Producer {
 		entity := otto.NewEntity(nil, nil, nil)
 		entity.ModelName = "plane"
 		entity.EntityType = "floor"
+		entity.BodyType = physics.BodyStatic
 		// Make the floor world-wide with a much larger scale
 		entity.Scale = mgl64.Vec3{1000, 1, 1000} // 1000x1000 world units
 		// Set initial position at y=0
 		entity.Position = mgl64.Vec3{0, 0, 0}
+		// Rolling hills around the flat middle of the world, where the cubes are dropped
+		entity.Shape = physics.HeightfieldShape(physics.NewHeightfield(251, 251, 4, hills))
 
 		return &Floor{
 			Entity:      entity,
@@ -36,8 +40,15 @@ func New(renderPID, physicsPID *actor.PID) actor.Producer {
 	}
 }
 
+// hills raises the terrain smoothly from nothing at 150 units from the center
+// of the world to rolling hills of up to 6 units further out
+func hills(x, z float64) float64 {
+	blend := math.Min(math.Max((math.Hypot(x, z)-150)/50, 0), 1)
+	return blend * 3 * (1 + math.Sin(x/25)*math.Cos(z/25))
+}
+
 func (f *Floor) Receive(ctx *actor.Context) {
-	switch msg := ctx.Message().(type) {
+	switch ctx.Message().(type) {
 	case actor.Started:
 		// Register with renderer
 		ctx.Send(f.rendererPID, renderer.EventEntityRegister{
@@ -49,13 +60,5 @@ func (f *Floor) Receive(ctx *actor.Context) {
 			PID:             ctx.PID(),
 			EntityRigidBody: f.Entity.ToRigidBody(),
 		})
-	case physics.EventPositionUpdate:
-		// Keep the floor fixed at y=0 regardless of any position updates
-		floorPosition := mgl64.Vec3{msg.Position.X(), 0, msg.Position.Z()}
-		f.Entity.Position = floorPosition
-		ctx.Send(f.rendererPID, renderer.EventEntityRenderUpdate{
-			PID:             ctx.PID(),
-			EntityRigidBody: f.Entity.ToRigidBody(),
-		})
 	}
 }
@@ -7,6 +7,7 @@ import (
 	"otto"
 	"otto/cmd/playground/cube"
 	"otto/cmd/playground/player"
+	"otto/cmd/playground/vehicle"
 	"otto/manager"
 	"otto/monitoring"
 	"otto/system"
@@ -47,6 +48,13 @@ func main() {
 
 	e.Spawn(player.NewPlayer(physicsPID, rendererPID, inputPID), "player", actor.WithMiddleware(actorTracker.WithActorTracking("player")))
 
+	// A car driven with the arrow keys, parked beside the cubes
+	e.Spawn(
+		vehicle.NewVehicle(physicsPID, rendererPID, inputPID, mgl64.Vec3{-10, 1.2, 0}),
+		"vehicle",
+		actor.WithMiddleware(actorTracker.WithActorTracking("vehicle")),
+	)
+
 	// Spawn 100 cubes in a 10x10 grid with a gap of 1 cube between them
 	// Each cube is 1 unit, so we place them 2 units apart (1 unit for cube + 1 unit gap)
 	// Using batch rendering for better performance
@@ -85,6 +93,18 @@ func main() {
 	}
 	defer modelManager.Cleanup()
 
+	// Share model extents with physics so entities collide with their visual size
+	for _, modelName := range modelManager.GetLoadedModels() {
+		model, err := modelManager.Model(modelName)
+		if err != nil {
+			continue
+		}
+		e.Send(physicsPID, physics.EventModelBounds{
+			ModelName: modelName,
+			Bounds:    model.Bounds,
+		})
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
@@ -136,6 +156,20 @@ func main() {
 		}
 	}(ctx)
 
+	// Follow the size of the window in the metrics, resizes come from the render thread
+	go func(ctx context.Context, events <-chan otto.WindowEvent) {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event := <-events:
+				if resize, ok := event.(otto.EventWindowResize); ok {
+					metricsManager.UpdateWindowSize(resize.FramebufferWidth, resize.FramebufferHeight)
+				}
+			}
+		}
+	}(ctx, window.Subscribe())
+
 	// FPS tracking variables
 	var lastFPSUpdate time.Time
 	var currentFPS float64
@@ -146,6 +180,22 @@ func main() {
 	var lastMemoryUpdate time.Time
 	memoryUpdateInterval := 5 * time.Second
 
+	// Entities drawn and culled by the last frame, shown in the next one
+	var visibleEntities, culledEntities int
+
+	// Physics debug geometry toggled from the debug panel
+	var debugDraw physics.DebugDraw
+	var debugLines []physics.DebugLine
+	debugOptions := []struct {
+		label string
+		draw  physics.DebugDraw
+	}{
+		{"Colliders", physics.DebugColliders},
+		{"Contact normals", physics.DebugContacts},
+		{"AABBs", physics.DebugAABBs},
+		{"Velocities", physics.DebugVelocities},
+	}
+
 	window.Run(func(deltaTime float64) {
 		// Track frame time for FPS calculation
 		if len(frameTimes) >= maxFrameTimes {
@@ -211,6 +261,7 @@ func main() {
 		imgui.Text(fmt.Sprintf("Frame Time: %.3f ms", deltaTime*1000))
 		imgui.Text(fmt.Sprintf("Tick Rate: %d Hz", serverTickRate))
 		imgui.Text(fmt.Sprintf("Entities: %d", len(response.Entities)))
+		imgui.Text(fmt.Sprintf("Visible: %d, culled: %d", visibleEntities, culledEntities))
 		if metricsManager.IsEnabled() {
 			imgui.Text("Metrics: ENABLED")
 			imgui.Text("Dashboard: http://localhost:3030 (admin/admin)")
@@ -220,22 +271,54 @@ func main() {
 		}
 		imgui.End()
 
+		// Render physics debug panel
+		imgui.Begin("Physics Debug")
+		for _, option := range debugOptions {
+			enabled := debugDraw&option.draw != 0
+			if imgui.Checkbox(option.label, &enabled) {
+				debugDraw ^= option.draw
+				e.Send(physicsPID, physics.EventSetDebugDraw{Draw: debugDraw})
+			}
+		}
+		imgui.Text("Green: awake, blue: sleeping, grey: static")
+		imgui.Text("Yellow: kinematic, magenta: sensor")
+		imgui.End()
+
 		// Render entities using OpenGL batch rendering for better performance
 		var floor physics.EntityRigidBody
 		entities := make([]*physics.EntityRigidBody, 0, len(response.Entities))
+		previous := make([]*physics.EntityRigidBody, 0, len(response.Previous))
+		tints := make([]mgl64.Vec4, 0, len(response.Tints))
 		for i := range response.Entities {
 			if response.Entities[i].ModelName == "plane" {
 				floor = response.Entities[i]
 				continue
 			}
 			entities = append(entities, &response.Entities[i])
+			previous = append(previous, &response.Previous[i])
+			tints = append(tints, response.Tints[i])
 		}
 
 		// Record frame time for metrics
 		frameStart := time.Now()
 
-		otto.RenderGridFloor(shaderManager, modelManager, &floor, &response.Camera)
-		otto.RenderEntityBatch(shaderManager, modelManager, entities, &response.Camera)
+		if floor.Shape != nil && floor.Shape.Heightfield != nil {
+			otto.RenderTerrain(shaderManager, &floor, &response.Camera, window)
+		} else {
+			otto.RenderGridFloor(shaderManager, modelManager, &floor, &response.Camera, window)
+		}
+		visibleEntities, culledEntities = otto.RenderEntityBatch(shaderManager, modelManager, entities, previous, tints, response.Alpha, &response.Camera, window)
+		metricsManager.UpdateCulledEntities(visibleEntities, culledEntities)
+
+		// Draw the latest debug geometry, keeping the previous one when physics is too busy to answer
+		if debugDraw != 0 {
+			if res, err := e.Request(physicsPID, physics.RequestDebugGeometry{}, 50*time.Millisecond).Result(); err == nil {
+				if geometry, ok := res.(physics.DebugGeometryResponse); ok {
+					debugLines = geometry.Lines
+				}
+			}
+			otto.RenderDebugLines(shaderManager, debugLines, &response.Camera, window)
+		}
 
 		// Update render calls metric
 		metricsManager.IncrementRenderCalls()
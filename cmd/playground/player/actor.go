@@ -22,11 +22,17 @@ type Player struct {
 	cameraPID *actor.PID
 	floorPID  *actor.PID
 
-	// Jump system
-	isOnGround bool
-	canJump    bool
+	// Look direction as pitch and yaw from the camera, separate from the body
+	// orientation so that looking around never rotates the collider
+	look mgl64.Vec3
+
+	// Jumps are sent once per press of the jump key, holding it does not repeat them
+	jumpHeld bool
 }
 
+// moveSpeed is the walking speed in world units per second
+const moveSpeed = 7.0
+
 var _ actor.Receiver = (*Player)(nil)
 
 func NewPlayer(physicsPID, rendererPID, inputPID *actor.PID) actor.Producer {
@@ -45,6 +51,8 @@ func (p *Player) Receive(ctx *actor.Context) {
 	case actor.Initialized:
 		// Set the player entity type
 		p.Entity.EntityType = "player"
+		p.Entity.Material = &physics.CharacterMaterial
+		p.Entity.Character = &physics.DefaultCharacterController
 
 		p.cameraPID = ctx.SpawnChild(camera.New(p.physicsPID, p.rendererPID, p.inputPID), "camera")
 		p.floorPID = ctx.SpawnChild(floor.New(p.rendererPID, p.physicsPID), "floor")
@@ -68,7 +76,7 @@ func (p *Player) Receive(ctx *actor.Context) {
 	case input.EventInput:
 		p.HandleInput(ctx, msg)
 	case physics.EventRotationUpdate:
-		p.Entity.Rotation = msg.Rotation
+		p.look = msg.Rotation
 	case physics.EventRigidBodyTransform:
 		ctx.Send(p.cameraPID, physics.EventPositionUpdate{
 			PID:      ctx.PID(),
@@ -79,11 +87,6 @@ func (p *Player) Receive(ctx *actor.Context) {
 		// 	PID:      ctx.PID(),
 		// 	Position: msg.Position,
 		// })
-	case physics.EventGroundState:
-		p.isOnGround = msg.IsOnGround
-		if p.isOnGround {
-			p.canJump = true // Reset jump ability when on ground
-		}
 	}
 }
 
@@ -91,8 +94,8 @@ func (p *Player) HandleInput(ctx *actor.Context, event input.EventInput) {
 	switch input := event.Context.(type) {
 	case *InputPlayerMovement:
 		// Use camera vectors to transform velocity into world space
-		front := util.Vec3FrontVector(p.Entity.Rotation)
-		right := util.Vec3RightVector(p.Entity.Rotation)
+		front := util.Vec3FrontVector(p.look)
+		right := util.Vec3RightVector(p.look)
 
 		// Create horizontal-only versions of front and right vectors (Y = 0)
 		frontHorizontal := mgl64.Vec3{front.X(), 0, front.Z()}.Normalize()
@@ -100,29 +103,19 @@ func (p *Player) HandleInput(ctx *actor.Context, event input.EventInput) {
 
 		// Transform horizontal movement (X and Z) using camera-relative vectors
 		horizontalVelocity := rightHorizontal.Mul(input.Velocity.X()).
-			Add(frontHorizontal.Mul(input.Velocity.Z()))
-
-		// Handle jumping (SPACE key)
-		var verticalVelocity mgl64.Vec3
-		if input.Velocity.Y() < 0 && p.canJump && p.isOnGround {
-			jumpForce := 1.0
-			verticalVelocity = mgl64.Vec3{0, jumpForce, 0}
-			p.canJump = false // Prevent multiple jumps
-		} else if input.Velocity.Y() > 0 {
-			// SHIFT key pressed (Y=1) - ignore it to prevent flying
-			// Don't add any vertical velocity
-		} else if input.Velocity.Y() == 0 {
-			// Reset jump ability when SPACE is released
-			p.canJump = true
-		}
+			Add(frontHorizontal.Mul(input.Velocity.Z())).
+			Mul(moveSpeed)
 
-		// Combine horizontal and vertical movement
-		velocity := horizontalVelocity.Add(verticalVelocity)
+		// SPACE (Y=-1) jumps, the character controller decides whether the jump
+		// can happen now or later. SHIFT (Y=1) is ignored to prevent flying.
+		jumpPressed := input.Velocity.Y() < 0
+		jump := jumpPressed && !p.jumpHeld
+		p.jumpHeld = jumpPressed
 
-		ctx.Send(p.physicsPID, physics.EventRigidBodyUpdate{
-			PID:             ctx.PID(),
-			Velocity:        velocity,
-			AngularVelocity: mgl64.Vec3{}, // No rotation for movement input
+		ctx.Send(p.physicsPID, physics.EventCharacterMove{
+			PID:      ctx.PID(),
+			Velocity: horizontalVelocity,
+			Jump:     jump,
 		})
 	}
 }
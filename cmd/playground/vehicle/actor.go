@@ -0,0 +1,62 @@
+package vehicle
+
+import (
+	"otto"
+	"otto/system/input"
+	"otto/system/physics"
+
+	"github.com/anthdm/hollywood/actor"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+type Vehicle struct {
+	*otto.Entity
+	physicsPID *actor.PID
+	inputPID   *actor.PID
+}
+
+var _ actor.Receiver = (*Vehicle)(nil)
+
+func NewVehicle(physicsPID, rendererPID, inputPID *actor.PID, position mgl64.Vec3) actor.Producer {
+	return func() actor.Receiver {
+		entity := otto.NewEntity(physicsPID, rendererPID, inputPID)
+		entity.ModelName = "cube"
+		entity.Tint = mgl64.Vec4{0.9, 0.2, 0.15, 1} // Red, to stand out from the cubes
+		entity.EntityType = "vehicle"
+		entity.Position = position
+		entity.Scale = mgl64.Vec3{2, 1, 4}
+		entity.Mass = 10
+		entity.Material = &physics.VehicleMaterial
+		entity.Vehicle = &physics.DefaultVehicleController
+		return &Vehicle{Entity: entity, physicsPID: physicsPID, inputPID: inputPID}
+	}
+}
+
+// Receive implements actor.Receiver.
+func (v *Vehicle) Receive(ctx *actor.Context) {
+	switch msg := ctx.Message().(type) {
+	case actor.Initialized:
+		input.RegisterInputs(
+			ctx,
+			v.inputPID,
+			&InputVehicleDrive{PID: ctx.PID()},
+		)
+		v.Entity.Receive(ctx)
+	case input.EventInput:
+		v.HandleInput(ctx, msg)
+	default:
+		v.Entity.Receive(ctx)
+	}
+}
+
+func (v *Vehicle) HandleInput(ctx *actor.Context, event input.EventInput) {
+	switch input := event.Context.(type) {
+	case *InputVehicleDrive:
+		ctx.Send(v.physicsPID, physics.EventVehicleInput{
+			PID:      ctx.PID(),
+			Throttle: input.Throttle,
+			Steering: input.Steering,
+			Brake:    input.Brake,
+		})
+	}
+}
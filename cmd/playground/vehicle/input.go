@@ -0,0 +1,53 @@
+package vehicle
+
+import (
+	"otto/system/input"
+
+	"github.com/anthdm/hollywood/actor"
+)
+
+type InputVehicleDrive struct {
+	PID      *actor.PID
+	Throttle float64
+	Steering float64
+	Brake    float64
+}
+
+var _ input.Context = (*InputVehicleDrive)(nil)
+
+// GetPID returns the PID of the input context
+func (h *InputVehicleDrive) GetPID() *actor.PID {
+	return h.PID
+}
+
+// Process drives the vehicle with the arrow keys, braking with B
+func (h *InputVehicleDrive) Process(deltaTime float64, state *input.InputState, captureKeyboard, captureMouse bool) bool {
+	h.Throttle, h.Steering, h.Brake = 0, 0, 0
+
+	// Skip keyboard input if UI wants to capture it
+	if captureKeyboard {
+		return false
+	}
+
+	if state.IsKeyPressed(input.KeyUp) {
+		h.Throttle++ // Forward (Z+)
+	}
+
+	if state.IsKeyPressed(input.KeyDown) {
+		h.Throttle-- // Reverse (Z-)
+	}
+
+	if state.IsKeyPressed(input.KeyLeft) {
+		h.Steering++ // Left (X+)
+	}
+
+	if state.IsKeyPressed(input.KeyRight) {
+		h.Steering-- // Right (X-)
+	}
+
+	if state.IsKeyPressed(input.KeyB) {
+		h.Brake = 1
+	}
+
+	return h.Throttle != 0 || h.Steering != 0 || h.Brake != 0
+}
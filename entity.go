@@ -9,12 +9,26 @@ import (
 )
 
 type Entity struct {
-	Position   mgl64.Vec3
-	Velocity   mgl64.Vec3
-	Scale      mgl64.Vec3
-	Rotation   mgl64.Vec3
-	ModelName  string
-	EntityType string // "player", "cube", "floor", etc.
+	BodyType            physics.BodyType
+	Layer               uint32 // Zero uses physics.LayerDefault
+	CollisionMask       uint32 // Layers the entity collides with, zero matches every layer
+	Sensor              bool   // Only reports overlaps through trigger events
+	Position            mgl64.Vec3
+	Velocity            mgl64.Vec3
+	Scale               mgl64.Vec3
+	Orientation         mgl64.Quat
+	Shape               *physics.Shape // Nil collides as the box given by the model bounds and the scale
+	FixedRotation       bool           // Never rotated by contacts, used by characters
+	ContinuousCollision bool           // Swept every step so fast entities cannot pass through thin walls
+	Mass                float64
+	GravityScale        *float64                     // Nil uses 1
+	Material            *physics.PhysicsMaterial     // Nil uses physics.DefaultMaterial
+	Character           *physics.CharacterController // Moves the entity with the character controller
+	ForceField          *physics.ForceField          // Pushes the bodies inside instead of colliding
+	Vehicle             *physics.VehicleController   // Drives the entity on raycast wheels
+	ModelName           string
+	Tint                mgl64.Vec4 // Color the model is drawn with, zero draws it white
+	EntityType          string     // "player", "cube", "floor", etc.
 
 	physicsPID  *actor.PID
 	rendererPID *actor.PID
@@ -26,6 +40,8 @@ var _ actor.Receiver = (*Entity)(nil)
 func NewEntity(physicsPID, rendererPID, inputPID *actor.PID) *Entity {
 	return &Entity{
 		Scale:       mgl64.Vec3{1, 1, 1},
+		Orientation: mgl64.QuatIdent(),
+		Mass:        1,
 		physicsPID:  physicsPID,
 		rendererPID: rendererPID,
 		inputPID:    inputPID,
@@ -48,13 +64,16 @@ func (e *Entity) Receive(ctx *actor.Context) {
 			ctx.Send(e.rendererPID, renderer.EventEntityRegister{
 				PID:             ctx.PID(),
 				EntityRigidBody: e.ToRigidBody(),
+				Tint:            e.Tint,
 			})
 		}
 	case physics.EventRigidBodyTransform:
 		e.Transform(ctx, msg)
 		if e.rendererPID != nil {
+			previous := e.ToRigidBody()
+			previous.Position, previous.Orientation = msg.PreviousPosition, msg.PreviousOrientation
 			ctx.Send(e.rendererPID, renderer.EventEntityRenderUpdate{
-				PID: ctx.PID(), EntityRigidBody: e.ToRigidBody(),
+				PID: ctx.PID(), EntityRigidBody: e.ToRigidBody(), Previous: previous, Tint: e.Tint, Step: msg.Step, Alpha: msg.Alpha,
 			})
 		}
 	}
@@ -62,16 +81,29 @@ func (e *Entity) Receive(ctx *actor.Context) {
 
 func (e *Entity) ToRigidBody() physics.EntityRigidBody {
 	return physics.EntityRigidBody{
-		Position:   e.Position,
-		Velocity:   e.Velocity,
-		Scale:      e.Scale,
-		Rotation:   e.Rotation,
-		ModelName:  e.ModelName,
-		EntityType: e.EntityType,
+		BodyType:            e.BodyType,
+		Layer:               e.Layer,
+		CollisionMask:       e.CollisionMask,
+		Sensor:              e.Sensor,
+		Position:            e.Position,
+		Velocity:            e.Velocity,
+		Scale:               e.Scale,
+		Orientation:         e.Orientation,
+		Shape:               e.Shape,
+		FixedRotation:       e.FixedRotation,
+		ContinuousCollision: e.ContinuousCollision,
+		Mass:                e.Mass,
+		GravityScale:        e.GravityScale,
+		Material:            e.Material,
+		Character:           e.Character,
+		ForceField:          e.ForceField,
+		Vehicle:             e.Vehicle,
+		ModelName:           e.ModelName,
+		EntityType:          e.EntityType,
 	}
 }
 
 func (e *Entity) Transform(ctx *actor.Context, msg physics.EventRigidBodyTransform) {
 	e.Position = msg.Position
-	e.Rotation = msg.Rotation
+	e.Orientation = msg.Orientation
 }
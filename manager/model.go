@@ -17,6 +17,10 @@ const (
 	FLOAT32_BYTES   = 4
 	POSITION_FLOATS = 3
 	TEXCOORD_FLOATS = 2
+
+	// INSTANCE_FLOATS is the size of the attributes of a single instance: a
+	// model matrix in column-major order followed by an RGBA tint
+	INSTANCE_FLOATS = 16 + 4
 )
 
 // Model represents a 3D model with its OpenGL buffers
@@ -25,6 +29,7 @@ type Model struct {
 	VAO      uint32
 	VBO      uint32
 	EBO      uint32
+	Instance uint32 // Buffer of the per-instance attributes, filled before every instanced draw
 	Indices  []uint32
 	Vertices []float32
 	Stride   int
@@ -183,11 +188,12 @@ func (m *ModelManager) loadModelFromFile(file string) (*Model, error) {
 		indices[i] = uint32(val)
 	}
 
-	var VAO, VBO, EBO uint32
+	var VAO, VBO, EBO, instanceVBO uint32
 
 	gl.GenVertexArrays(1, &VAO)
 	gl.GenBuffers(1, &VBO)
 	gl.GenBuffers(1, &EBO)
+	gl.GenBuffers(1, &instanceVBO)
 
 	gl.BindVertexArray(VAO)
 
@@ -217,12 +223,27 @@ func (m *ModelManager) loadModelFromFile(file string) (*Model, error) {
 		gl.EnableVertexAttribArray(2)
 	}
 
+	// Instance attributes advance once per instance rather than per vertex.
+	// The model matrix takes one location per column (locations 3 to 6),
+	// followed by the tint (location = 7).
+	gl.BindBuffer(gl.ARRAY_BUFFER, instanceVBO)
+	instanceStride := int32(INSTANCE_FLOATS * FLOAT32_BYTES)
+	for column := uint32(0); column < 4; column++ {
+		gl.VertexAttribPointerWithOffset(3+column, 4, gl.FLOAT, false, instanceStride, uintptr(column*4*FLOAT32_BYTES))
+		gl.EnableVertexAttribArray(3 + column)
+		gl.VertexAttribDivisor(3+column, 1)
+	}
+	gl.VertexAttribPointerWithOffset(7, 4, gl.FLOAT, false, instanceStride, uintptr(16*FLOAT32_BYTES))
+	gl.EnableVertexAttribArray(7)
+	gl.VertexAttribDivisor(7, 1)
+
 	gl.BindVertexArray(0)
 
 	model := &Model{
 		VAO:      VAO,
 		VBO:      VBO,
 		EBO:      EBO,
+		Instance: instanceVBO,
 		Indices:  indices,
 		Vertices: objModel.Coord,
 		Stride:   objModel.StrideSize,
@@ -318,6 +339,45 @@ func (m *Model) calculateBounds() mgl64.Vec3 {
 	}
 }
 
+// Points returns the position of every vertex of the model, such as for
+// building a convex hull collision shape around it
+func (m *Model) Points() []mgl64.Vec3 {
+	strideFloats := m.Stride / FLOAT32_BYTES
+	if strideFloats == 0 {
+		return nil
+	}
+
+	points := make([]mgl64.Vec3, 0, len(m.Vertices)/strideFloats)
+	for i := 0; i+POSITION_FLOATS <= len(m.Vertices); i += strideFloats {
+		points = append(points, m.position(i))
+	}
+	return points
+}
+
+// Triangles returns the triangles of the model as given by its indices, such
+// as for building a static triangle mesh collision shape from it
+func (m *Model) Triangles() [][3]mgl64.Vec3 {
+	strideFloats := m.Stride / FLOAT32_BYTES
+	triangles := make([][3]mgl64.Vec3, 0, len(m.Indices)/3)
+	for i := 0; i+2 < len(m.Indices); i += 3 {
+		var triangle [3]mgl64.Vec3
+		for v := range triangle {
+			triangle[v] = m.position(int(m.Indices[i+v]) * strideFloats)
+		}
+		triangles = append(triangles, triangle)
+	}
+	return triangles
+}
+
+// position returns the vertex position starting at an offset in floats
+func (m *Model) position(offset int) mgl64.Vec3 {
+	return mgl64.Vec3{
+		float64(m.Vertices[offset]),
+		float64(m.Vertices[offset+1]),
+		float64(m.Vertices[offset+2]),
+	}
+}
+
 // calculateVolume estimates the volume of the model using its bounding box
 func (m *Model) calculateVolume() float64 {
 	bounds := m.Bounds
@@ -330,6 +390,7 @@ func (m *ModelManager) Cleanup() {
 		gl.DeleteVertexArrays(1, &model.VAO)
 		gl.DeleteBuffers(1, &model.VBO)
 		gl.DeleteBuffers(1, &model.EBO)
+		gl.DeleteBuffers(1, &model.Instance)
 	}
 	m.models = make(map[string]*Model)
 
@@ -19,6 +19,23 @@ type ShaderFile struct {
 type ShaderProgram struct {
 	Name string
 	PID  uint32
+
+	uniforms map[string]int32 // Locations of the uniforms looked up so far
+}
+
+// Uniform returns the location of a uniform of the program. Every uniform is
+// only looked up the first time it is asked for, later calls use its cached
+// location. Copies of a loaded program share their cache.
+func (p *ShaderProgram) Uniform(name string) int32 {
+	if p.uniforms == nil {
+		p.uniforms = make(map[string]int32)
+	}
+	location, ok := p.uniforms[name]
+	if !ok {
+		location = gl.GetUniformLocation(p.PID, gl.Str(name+"\x00"))
+		p.uniforms[name] = location
+	}
+	return location
 }
 
 type ShaderManager struct {
@@ -81,8 +98,9 @@ func (s *ShaderManager) Init(path string) error {
 		}
 
 		s.programs[folderName] = ShaderProgram{
-			Name: folderName,
-			PID:  programHandle,
+			Name:     folderName,
+			PID:      programHandle,
+			uniforms: make(map[string]int32),
 		}
 	}
 
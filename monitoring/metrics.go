@@ -21,6 +21,16 @@ var (
 		Help: "Number of entities in the game world",
 	})
 
+	visibleEntitiesGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "otto_visible_entities",
+		Help: "Number of entities inside the camera frustum in the last frame",
+	})
+
+	culledEntitiesGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "otto_culled_entities",
+		Help: "Number of entities culled outside the camera frustum in the last frame",
+	})
+
 	renderCallsCounter = prometheus.NewCounter(prometheus.CounterOpts{
 		Name: "otto_render_calls_total",
 		Help: "Total number of render calls",
@@ -52,6 +62,11 @@ var (
 		Help: "Total number of actors in the system",
 	})
 
+	windowSizeGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "otto_window_size_pixels",
+		Help: "Size of the framebuffer of the window in pixels",
+	}, []string{"dimension"})
+
 	// Metrics registry
 	registry = prometheus.NewRegistry()
 )
@@ -70,12 +85,15 @@ func NewMetricsManager() *MetricsManager {
 		// Register all metrics
 		registry.MustRegister(fpsGauge)
 		registry.MustRegister(entityCountGauge)
+		registry.MustRegister(visibleEntitiesGauge)
+		registry.MustRegister(culledEntitiesGauge)
 		registry.MustRegister(renderCallsCounter)
 		registry.MustRegister(inputEventsCounter)
 		registry.MustRegister(physicsCalculationsCounter)
 		registry.MustRegister(frameTimeHistogram)
 		registry.MustRegister(memoryUsageGauge)
 		registry.MustRegister(actorCountGauge)
+		registry.MustRegister(windowSizeGauge)
 
 		// Register default Go metrics
 		registry.MustRegister(prometheus.NewGoCollector())
@@ -137,6 +155,14 @@ func (m *MetricsManager) UpdateEntityCount(count int) {
 	}
 }
 
+// UpdateCulledEntities updates the visible and culled entity count metrics
+func (m *MetricsManager) UpdateCulledEntities(visible, culled int) {
+	if m.enabled {
+		visibleEntitiesGauge.Set(float64(visible))
+		culledEntitiesGauge.Set(float64(culled))
+	}
+}
+
 // IncrementRenderCalls increments the render calls counter
 func (m *MetricsManager) IncrementRenderCalls() {
 	if m.enabled {
@@ -181,3 +207,11 @@ func (m *MetricsManager) UpdateActorCount(count int) {
 		actorCountGauge.Set(float64(count))
 	}
 }
+
+// UpdateWindowSize updates the window size metrics
+func (m *MetricsManager) UpdateWindowSize(width, height int) {
+	if m.enabled {
+		windowSizeGauge.WithLabelValues("width").Set(float64(width))
+		windowSizeGauge.WithLabelValues("height").Set(float64(height))
+	}
+}
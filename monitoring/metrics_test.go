@@ -16,11 +16,13 @@ func TestMetricsManager(t *testing.T) {
 	// Test metrics methods don't panic when disabled
 	manager.UpdateFPS(60.0)
 	manager.UpdateEntityCount(100)
+	manager.UpdateCulledEntities(60, 40)
 	manager.IncrementRenderCalls()
 	manager.IncrementInputEvents()
 	manager.IncrementPhysicsCalculations()
 	manager.RecordFrameTime(time.Millisecond * 16)
 	manager.UpdateMemoryUsage(1024, 2048, 512, 1536)
+	manager.UpdateWindowSize(1200, 900)
 
 	// Test stop doesn't panic
 	if err := manager.Stop(); err != nil {
@@ -10,133 +10,143 @@ import (
 
 	"github.com/go-gl/gl/v4.1-core/gl"
 	"github.com/go-gl/mathgl/mgl32"
+	"github.com/go-gl/mathgl/mgl64"
 )
 
+// instanceData is the scratch buffer the instance attributes of a model group
+// are written to before being uploaded, reused every frame
+var instanceData []float32
+
 // RenderEntityBatch renders multiple entities of the same model type in a single batch
-// This is more efficient than individual RenderEntity calls for many objects
-func RenderEntityBatch(shaderManager *manager.ShaderManager, modelManager *manager.ModelManager, entities []*physics.EntityRigidBody, camera *system.Camera) {
+// This is more efficient than individual RenderEntity calls for many objects.
+// Previous holds the transforms of the prior physics step aligned with entities, each
+// entity is drawn blended between both by alpha so motion stays smooth between steps.
+// Tints are aligned with entities too, a zero tint draws the entity white.
+// Every model is drawn with a single instanced draw call, the model matrix and tint
+// of each entity being uploaded to the instance buffer of the model. Entities whose
+// bounds are outside the view of the camera are skipped, it returns how many
+// entities were drawn and how many were culled.
+func RenderEntityBatch(shaderManager *manager.ShaderManager, modelManager *manager.ModelManager, entities, previous []*physics.EntityRigidBody, tints []mgl64.Vec4, alpha float64, camera *system.Camera, window Window) (visible, culled int) {
 	if len(entities) == 0 {
-		return
+		return 0, 0
 	}
 
 	// Group entities by model name for batch rendering
-	modelGroups := make(map[string][]*physics.EntityRigidBody)
-	for _, entity := range entities {
+	modelGroups := make(map[string][]int)
+	for i, entity := range entities {
 		if entity.ModelName == "" {
 			continue // Skip invisible entities
 		}
-		modelGroups[entity.ModelName] = append(modelGroups[entity.ModelName], entity)
+		modelGroups[entity.ModelName] = append(modelGroups[entity.ModelName], i)
 	}
 
-	// Render each model group in batches
-	for modelName, modelEntities := range modelGroups {
-		shaderProgram, err := shaderManager.Program("camera")
-		if err != nil {
-			log.Printf("Failed to get shader program: %v", err)
-			continue
-		}
-
-		model, err := modelManager.Model(modelName)
-		if err != nil {
-			log.Printf("Failed to get model %s: %v", modelName, err)
-			continue
-		}
+	shaderProgram, err := shaderManager.Program("camera")
+	if err != nil {
+		log.Printf("Failed to get shader program: %v", err)
+		return 0, 0
+	}
 
-		// Use shader program once for the entire batch
-		gl.UseProgram(shaderProgram.PID)
+	// Use shader program once for every model group
+	gl.UseProgram(shaderProgram.PID)
 
-		// Bind VAO once for the entire batch
-		gl.BindVertexArray(model.VAO)
+	// Set view and projection uniforms once (same for all entities)
+	cameraPos := util.Vec64ToVec32(camera.Position)
+	view, projection := cameraMatrices(camera, window)
+	gl.UniformMatrix4fv(shaderProgram.Uniform("view"), 1, false, &view[0])
+	gl.UniformMatrix4fv(shaderProgram.Uniform("projection"), 1, false, &projection[0])
+	frustum := util.NewFrustum(projection.Mul4(view))
+
+	// Set lighting uniforms once
+	gl.Uniform4f(shaderProgram.Uniform("color"), 1.0, 1.0, 1.0, 1.0)
+	gl.Uniform3f(shaderProgram.Uniform("viewPos"), cameraPos.X(), cameraPos.Y(), cameraPos.Z())
+	gl.Uniform1f(shaderProgram.Uniform("ambientStrength"), 0.3)
+	gl.Uniform1f(shaderProgram.Uniform("occlusionStrength"), 1.0)
+	gl.Uniform1i(shaderProgram.Uniform("numLights"), 1)
 
-		// Set up view and projection matrices once (same for all entities)
-		cameraPos := util.Vec64ToVec32(camera.Position)
-		pitch := camera.Rotation.X()
-		yaw := camera.Rotation.Y()
+	lightPos := mgl32.Vec3{1.0, 1.0, 1.0}
+	gl.Uniform3fv(shaderProgram.Uniform("lightPositions"), 1, &lightPos[0])
 
-		cosPitch := float32(math.Cos(pitch))
-		sinPitch := float32(math.Sin(pitch))
-		cosYaw := float32(math.Cos(yaw))
-		sinYaw := float32(math.Sin(yaw))
+	lightColor := mgl32.Vec3{1.0, 1.0, 1.0}
+	gl.Uniform3fv(shaderProgram.Uniform("lightColors"), 1, &lightColor[0])
 
-		forward := mgl32.Vec3{
-			cosPitch * sinYaw,
-			sinPitch,
-			cosPitch * cosYaw,
-		}
+	lightIntensity := float32(2.0)
+	gl.Uniform1f(shaderProgram.Uniform("lightIntensities"), lightIntensity)
 
-		up := mgl32.Vec3{0, 1, 0}
-		target := cameraPos.Add(forward)
-		view := mgl32.LookAtV(cameraPos, target, up)
+	// Transforms come from the instance buffer until the batch is done
+	instancedLocation := shaderProgram.Uniform("instanced")
+	gl.Uniform1i(instancedLocation, 1)
 
-		fov := float32(45.0 / camera.Zoom)
-		if fov < 5.0 {
-			fov = 5.0
-		}
-		if fov > 90.0 {
-			fov = 90.0
+	// Render each model group with a single draw call
+	for modelName, indices := range modelGroups {
+		model, err := modelManager.Model(modelName)
+		if err != nil {
+			log.Printf("Failed to get model %s: %v", modelName, err)
+			continue
 		}
-		projection := mgl32.Perspective(mgl32.DegToRad(fov), 1200.0/900.0, 0.1, 10_000.0)
-
-		// Set view and projection uniforms once
-		gl.UniformMatrix4fv(gl.GetUniformLocation(shaderProgram.PID, gl.Str("view\x00")), 1, false, &view[0])
-		gl.UniformMatrix4fv(gl.GetUniformLocation(shaderProgram.PID, gl.Str("projection\x00")), 1, false, &projection[0])
-
-		// Set lighting uniforms once
-		gl.Uniform4f(gl.GetUniformLocation(shaderProgram.PID, gl.Str("color\x00")), 1.0, 1.0, 1.0, 1.0)
-		gl.Uniform3f(gl.GetUniformLocation(shaderProgram.PID, gl.Str("viewPos\x00")), cameraPos.X(), cameraPos.Y(), cameraPos.Z())
-		gl.Uniform1f(gl.GetUniformLocation(shaderProgram.PID, gl.Str("ambientStrength\x00")), 0.3)
-		gl.Uniform1f(gl.GetUniformLocation(shaderProgram.PID, gl.Str("occlusionStrength\x00")), 1.0)
-		gl.Uniform1i(gl.GetUniformLocation(shaderProgram.PID, gl.Str("numLights\x00")), 1)
-
-		lightPos := mgl32.Vec3{1.0, 1.0, 1.0}
-		gl.Uniform3fv(gl.GetUniformLocation(shaderProgram.PID, gl.Str("lightPositions\x00")), 1, &lightPos[0])
-
-		lightColor := mgl32.Vec3{1.0, 1.0, 1.0}
-		gl.Uniform3fv(gl.GetUniformLocation(shaderProgram.PID, gl.Str("lightColors\x00")), 1, &lightColor[0])
-
-		lightIntensity := float32(2.0)
-		gl.Uniform1f(gl.GetUniformLocation(shaderProgram.PID, gl.Str("lightIntensities\x00")), lightIntensity)
-
-		// Render each entity in the batch
-		renderedEntities := 0
-		for _, entity := range modelEntities {
-			// Simple frustum culling: skip entities too far from camera
-			entityPos := util.Vec64ToVec32(entity.Position)
-			distance := cameraPos.Sub(entityPos).Len()
-			if distance > 1200.0 { // Skip entities more than 50 units away
-				continue
-			}
 
-			// Set up transformation matrices for this entity
+		instanceData = instanceData[:0]
+		for _, i := range indices {
+			entity := previous[i].Interpolate(*entities[i], alpha)
 			position := util.Vec64ToVec32(entity.Position)
 			scale := util.Vec64ToVec32(entity.Scale)
-			rotation := util.Vec64ToVec32(entity.Rotation)
+			rotation := util.Quat64ToMat4(entity.Orientation)
+
+			// Frustum culling: skip entities whose model bounds, scaled and
+			// rotated like the entity, are out of view
+			halfExtents := util.Vec64ToVec32(model.Bounds).Mul(0.5)
+			halfExtents = mgl32.Vec3{halfExtents.X() * scale.X(), halfExtents.Y() * scale.Y(), halfExtents.Z() * scale.Z()}
+			if !frustum.IntersectsBox(position, rotation, halfExtents) {
+				culled++
+				continue
+			}
+			visible++
 
-			modelMatrix := mgl32.Ident4()
-			modelMatrix = modelMatrix.Mul4(mgl32.Translate3D(position.X(), position.Y(), position.Z()))
+			// Set up transformation matrices for this entity
+			modelMatrix := mgl32.Translate3D(position.X(), position.Y(), position.Z())
+			modelMatrix = modelMatrix.Mul4(rotation)
 			modelMatrix = modelMatrix.Mul4(mgl32.Scale3D(scale.X(), scale.Y(), scale.Z()))
-			modelMatrix = modelMatrix.Mul4(mgl32.HomogRotate3D(rotation.X(), mgl32.Vec3{1, 0, 0}))
-			modelMatrix = modelMatrix.Mul4(mgl32.HomogRotate3D(rotation.Y(), mgl32.Vec3{0, 1, 0}))
-			modelMatrix = modelMatrix.Mul4(mgl32.HomogRotate3D(rotation.Z(), mgl32.Vec3{0, 0, 1}))
 
-			// Set model matrix for this entity
-			gl.UniformMatrix4fv(gl.GetUniformLocation(shaderProgram.PID, gl.Str("model\x00")), 1, false, &modelMatrix[0])
+			// Entities without a tint are drawn white
+			tint := mgl32.Vec4{1.0, 1.0, 1.0, 1.0}
+			if tints[i] != (mgl64.Vec4{}) {
+				tint = mgl32.Vec4{float32(tints[i].X()), float32(tints[i].Y()), float32(tints[i].Z()), float32(tints[i].W())}
+			}
+
+			instanceData = append(instanceData, modelMatrix[:]...)
+			instanceData = append(instanceData, tint[:]...)
+		}
 
-			// Draw the model
-			gl.DrawElements(gl.TRIANGLES, int32(len(model.Indices)), gl.UNSIGNED_INT, nil)
-			renderedEntities++
+		instances := len(instanceData) / manager.INSTANCE_FLOATS
+		if instances == 0 {
+			continue
 		}
 
+		// Bind VAO once for the entire batch
+		gl.BindVertexArray(model.VAO)
+
+		// Replace the instances of the last frame, orphaning the old storage so
+		// the driver never waits for draws still reading it
+		gl.BindBuffer(gl.ARRAY_BUFFER, model.Instance)
+		gl.BufferData(gl.ARRAY_BUFFER, len(instanceData)*4, gl.Ptr(instanceData), gl.STREAM_DRAW)
+
+		// Draw every instance of the model
+		gl.DrawElementsInstanced(gl.TRIANGLES, int32(len(model.Indices)), gl.UNSIGNED_INT, nil, int32(instances))
+
 		// Unbind VAO
 		gl.BindVertexArray(0)
 	}
 
+	// Other draws of the camera program use the model uniform
+	gl.Uniform1i(instancedLocation, 0)
+
 	// Unuse shader program
 	gl.UseProgram(0)
+
+	return visible, culled
 }
 
 // RenderGridFloor renders a grid floor at Y=0 using line primitives
-func RenderGridFloor(shaderManager *manager.ShaderManager, modelManager *manager.ModelManager, floor *physics.EntityRigidBody, camera *system.Camera) {
+func RenderGridFloor(shaderManager *manager.ShaderManager, modelManager *manager.ModelManager, floor *physics.EntityRigidBody, camera *system.Camera, window Window) {
 	shaderProgram, err := shaderManager.Program("camera")
 	if err != nil {
 		log.Printf("Failed to get camera shader program: %v", err)
@@ -148,56 +158,31 @@ func RenderGridFloor(shaderManager *manager.ShaderManager, modelManager *manager
 
 	// Set up view and projection matrices
 	cameraPos := util.Vec64ToVec32(camera.Position)
-	pitch := camera.Rotation.X()
-	yaw := camera.Rotation.Y()
-
-	cosPitch := float32(math.Cos(pitch))
-	sinPitch := float32(math.Sin(pitch))
-	cosYaw := float32(math.Cos(yaw))
-	sinYaw := float32(math.Sin(yaw))
-
-	forward := mgl32.Vec3{
-		cosPitch * sinYaw,
-		sinPitch,
-		cosPitch * cosYaw,
-	}
-
-	up := mgl32.Vec3{0, 1, 0}
-	target := cameraPos.Add(forward)
-	view := mgl32.LookAtV(cameraPos, target, up)
-
-	fov := float32(45.0 / camera.Zoom)
-	if fov < 5.0 {
-		fov = 5.0
-	}
-	if fov > 90.0 {
-		fov = 90.0
-	}
-	projection := mgl32.Perspective(mgl32.DegToRad(fov), 1200.0/900.0, 0.1, 10_000.0)
+	view, projection := cameraMatrices(camera, window)
 
 	// Set up model matrix to position grid at floor position
 	floorPos := util.Vec64ToVec32(floor.Position)
 	modelMatrix := mgl32.Translate3D(floorPos.X(), floorPos.Y(), floorPos.Z())
 
-	gl.UniformMatrix4fv(gl.GetUniformLocation(shaderProgram.PID, gl.Str("model\x00")), 1, false, &modelMatrix[0])
-	gl.UniformMatrix4fv(gl.GetUniformLocation(shaderProgram.PID, gl.Str("view\x00")), 1, false, &view[0])
-	gl.UniformMatrix4fv(gl.GetUniformLocation(shaderProgram.PID, gl.Str("projection\x00")), 1, false, &projection[0])
+	gl.UniformMatrix4fv(shaderProgram.Uniform("model"), 1, false, &modelMatrix[0])
+	gl.UniformMatrix4fv(shaderProgram.Uniform("view"), 1, false, &view[0])
+	gl.UniformMatrix4fv(shaderProgram.Uniform("projection"), 1, false, &projection[0])
 
 	// Set lighting uniforms
-	gl.Uniform4f(gl.GetUniformLocation(shaderProgram.PID, gl.Str("color\x00")), 0.8, 0.8, 0.8, 1.0) // Light gray grid
-	gl.Uniform3f(gl.GetUniformLocation(shaderProgram.PID, gl.Str("viewPos\x00")), cameraPos.X(), cameraPos.Y(), cameraPos.Z())
-	gl.Uniform1f(gl.GetUniformLocation(shaderProgram.PID, gl.Str("ambientStrength\x00")), 0.3)
-	gl.Uniform1f(gl.GetUniformLocation(shaderProgram.PID, gl.Str("occlusionStrength\x00")), 1.0)
-	gl.Uniform1i(gl.GetUniformLocation(shaderProgram.PID, gl.Str("numLights\x00")), 1)
+	gl.Uniform4f(shaderProgram.Uniform("color"), 0.8, 0.8, 0.8, 1.0) // Light gray grid
+	gl.Uniform3f(shaderProgram.Uniform("viewPos"), cameraPos.X(), cameraPos.Y(), cameraPos.Z())
+	gl.Uniform1f(shaderProgram.Uniform("ambientStrength"), 0.3)
+	gl.Uniform1f(shaderProgram.Uniform("occlusionStrength"), 1.0)
+	gl.Uniform1i(shaderProgram.Uniform("numLights"), 1)
 
 	lightPos := mgl32.Vec3{1.0, 1.0, 1.0}
-	gl.Uniform3fv(gl.GetUniformLocation(shaderProgram.PID, gl.Str("lightPositions\x00")), 1, &lightPos[0])
+	gl.Uniform3fv(shaderProgram.Uniform("lightPositions"), 1, &lightPos[0])
 
 	lightColor := mgl32.Vec3{1.0, 1.0, 1.0}
-	gl.Uniform3fv(gl.GetUniformLocation(shaderProgram.PID, gl.Str("lightColors\x00")), 1, &lightColor[0])
+	gl.Uniform3fv(shaderProgram.Uniform("lightColors"), 1, &lightColor[0])
 
 	lightIntensity := float32(2.0)
-	gl.Uniform1f(gl.GetUniformLocation(shaderProgram.PID, gl.Str("lightIntensities\x00")), lightIntensity)
+	gl.Uniform1f(shaderProgram.Uniform("lightIntensities"), lightIntensity)
 
 	// Create grid data centered at floor position
 	gridSize := float32(floor.Scale.X()) // Grid size in world units (100x100)
@@ -282,3 +267,205 @@ func RenderGridFloor(shaderManager *manager.ShaderManager, modelManager *manager
 	// Unuse shader program
 	gl.UseProgram(0)
 }
+
+// terrainMesh is the vertex buffer of a heightfield, built the first time the
+// heightfield is drawn and reused afterwards
+type terrainMesh struct {
+	VAO, VBO uint32
+	vertices int32
+}
+
+// terrainMeshes holds the meshes of the heightfields drawn so far
+var terrainMeshes = make(map[*physics.Heightfield]*terrainMesh)
+
+// RenderTerrain renders the heightfield of a terrain entity with the same
+// triangles the physics system collides with
+func RenderTerrain(shaderManager *manager.ShaderManager, terrain *physics.EntityRigidBody, camera *system.Camera, window Window) {
+	if terrain.Shape == nil || terrain.Shape.Heightfield == nil {
+		return
+	}
+
+	shaderProgram, err := shaderManager.Program("camera")
+	if err != nil {
+		log.Printf("Failed to get camera shader program: %v", err)
+		return
+	}
+
+	mesh, ok := terrainMeshes[terrain.Shape.Heightfield]
+	if !ok {
+		mesh = newTerrainMesh(terrain.Shape.Heightfield)
+		terrainMeshes[terrain.Shape.Heightfield] = mesh
+	}
+
+	gl.UseProgram(shaderProgram.PID)
+
+	cameraPos := util.Vec64ToVec32(camera.Position)
+	view, projection := cameraMatrices(camera, window)
+	position := util.Vec64ToVec32(terrain.Position)
+	modelMatrix := mgl32.Translate3D(position.X(), position.Y(), position.Z())
+
+	gl.UniformMatrix4fv(shaderProgram.Uniform("model"), 1, false, &modelMatrix[0])
+	gl.UniformMatrix4fv(shaderProgram.Uniform("view"), 1, false, &view[0])
+	gl.UniformMatrix4fv(shaderProgram.Uniform("projection"), 1, false, &projection[0])
+
+	// Set lighting uniforms
+	gl.Uniform4f(shaderProgram.Uniform("color"), 0.45, 0.6, 0.35, 1.0) // Grassy green
+	gl.Uniform3f(shaderProgram.Uniform("viewPos"), cameraPos.X(), cameraPos.Y(), cameraPos.Z())
+	gl.Uniform1f(shaderProgram.Uniform("ambientStrength"), 0.3)
+	gl.Uniform1f(shaderProgram.Uniform("occlusionStrength"), 1.0)
+	gl.Uniform1i(shaderProgram.Uniform("numLights"), 1)
+
+	lightPos := mgl32.Vec3{1.0, 1.0, 1.0}
+	gl.Uniform3fv(shaderProgram.Uniform("lightPositions"), 1, &lightPos[0])
+
+	lightColor := mgl32.Vec3{1.0, 1.0, 1.0}
+	gl.Uniform3fv(shaderProgram.Uniform("lightColors"), 1, &lightColor[0])
+
+	lightIntensity := float32(2.0)
+	gl.Uniform1f(shaderProgram.Uniform("lightIntensities"), lightIntensity)
+
+	gl.BindVertexArray(mesh.VAO)
+	gl.DrawArrays(gl.TRIANGLES, 0, mesh.vertices)
+	gl.BindVertexArray(0)
+
+	gl.UseProgram(0)
+}
+
+// newTerrainMesh uploads the triangles of a heightfield, each with the normal
+// of its face so the slopes are lit like the surface bodies collide with
+func newTerrainMesh(heightfield *physics.Heightfield) *terrainMesh {
+	triangles := heightfield.Triangles()
+	coordData := make([]float32, 0, len(triangles)*3*8)
+	for _, triangle := range triangles {
+		normal := util.Vec64ToVec32(triangle[1].Sub(triangle[0]).Cross(triangle[2].Sub(triangle[0])).Normalize())
+		for _, vertex := range triangle {
+			position := util.Vec64ToVec32(vertex)
+			coordData = append(coordData, position.X(), position.Y(), position.Z(), 0.0, 0.0, normal.X(), normal.Y(), normal.Z()) // position, texcoord, normal
+		}
+	}
+
+	mesh := &terrainMesh{vertices: int32(len(triangles) * 3)}
+
+	gl.GenVertexArrays(1, &mesh.VAO)
+	gl.GenBuffers(1, &mesh.VBO)
+
+	gl.BindVertexArray(mesh.VAO)
+
+	gl.BindBuffer(gl.ARRAY_BUFFER, mesh.VBO)
+	gl.BufferData(gl.ARRAY_BUFFER, len(coordData)*4, gl.Ptr(coordData), gl.STATIC_DRAW)
+
+	stride := int32(8 * 4) // 8 floats per vertex * 4 bytes per float
+
+	// Position attribute (location = 0)
+	gl.VertexAttribPointerWithOffset(0, 3, gl.FLOAT, false, stride, 0)
+	gl.EnableVertexAttribArray(0)
+
+	// Texture coordinate attribute (location = 1)
+	gl.VertexAttribPointerWithOffset(1, 2, gl.FLOAT, false, stride, uintptr(3*4))
+	gl.EnableVertexAttribArray(1)
+
+	// Normal attribute (location = 2)
+	gl.VertexAttribPointerWithOffset(2, 3, gl.FLOAT, false, stride, uintptr(5*4))
+	gl.EnableVertexAttribArray(2)
+
+	gl.BindVertexArray(0)
+
+	return mesh
+}
+
+// debugLineBuffer is the vertex buffer the debug lines are streamed into,
+// created the first time lines are drawn and reused every frame
+type debugLineBuffer struct {
+	VAO, VBO uint32
+}
+
+// debugLines holds the buffer of RenderDebugLines
+var debugLines *debugLineBuffer
+
+// RenderDebugLines renders the debug geometry of the physics system as unlit
+// colored lines on top of the scene
+func RenderDebugLines(shaderManager *manager.ShaderManager, lines []physics.DebugLine, camera *system.Camera, window Window) {
+	if len(lines) == 0 {
+		return
+	}
+
+	shaderProgram, err := shaderManager.Program("debug")
+	if err != nil {
+		log.Printf("Failed to get debug shader program: %v", err)
+		return
+	}
+
+	if debugLines == nil {
+		debugLines = &debugLineBuffer{}
+		gl.GenVertexArrays(1, &debugLines.VAO)
+		gl.GenBuffers(1, &debugLines.VBO)
+
+		gl.BindVertexArray(debugLines.VAO)
+		gl.BindBuffer(gl.ARRAY_BUFFER, debugLines.VBO)
+
+		stride := int32(6 * 4) // 6 floats per vertex * 4 bytes per float
+
+		// Position attribute (location = 0)
+		gl.VertexAttribPointerWithOffset(0, 3, gl.FLOAT, false, stride, 0)
+		gl.EnableVertexAttribArray(0)
+
+		// Color attribute (location = 1)
+		gl.VertexAttribPointerWithOffset(1, 3, gl.FLOAT, false, stride, uintptr(3*4))
+		gl.EnableVertexAttribArray(1)
+	}
+
+	coordData := make([]float32, 0, len(lines)*2*6)
+	for _, line := range lines {
+		color := util.Vec64ToVec32(line.Color)
+		for _, point := range [2]mgl32.Vec3{util.Vec64ToVec32(line.From), util.Vec64ToVec32(line.To)} {
+			coordData = append(coordData, point.X(), point.Y(), point.Z(), color.X(), color.Y(), color.Z()) // position, color
+		}
+	}
+
+	gl.UseProgram(shaderProgram.PID)
+
+	view, projection := cameraMatrices(camera, window)
+	gl.UniformMatrix4fv(shaderProgram.Uniform("view"), 1, false, &view[0])
+	gl.UniformMatrix4fv(shaderProgram.Uniform("projection"), 1, false, &projection[0])
+
+	gl.BindVertexArray(debugLines.VAO)
+	gl.BindBuffer(gl.ARRAY_BUFFER, debugLines.VBO)
+	gl.BufferData(gl.ARRAY_BUFFER, len(coordData)*4, gl.Ptr(coordData), gl.STREAM_DRAW)
+
+	// Lines are drawn over the scene so colliders inside other bodies stay visible
+	gl.Disable(gl.DEPTH_TEST)
+	gl.DrawArrays(gl.LINES, 0, int32(len(lines)*2))
+	gl.Enable(gl.DEPTH_TEST)
+
+	gl.BindVertexArray(0)
+
+	gl.UseProgram(0)
+}
+
+// cameraMatrices returns the view and projection matrices of the camera, the
+// projection matching the aspect ratio of the framebuffer of the window
+func cameraMatrices(camera *system.Camera, window Window) (mgl32.Mat4, mgl32.Mat4) {
+	cameraPos := util.Vec64ToVec32(camera.Position)
+	pitch := camera.Rotation.X()
+	yaw := camera.Rotation.Y()
+
+	forward := mgl32.Vec3{
+		float32(math.Cos(pitch) * math.Sin(yaw)),
+		float32(math.Sin(pitch)),
+		float32(math.Cos(pitch) * math.Cos(yaw)),
+	}
+	view := mgl32.LookAtV(cameraPos, cameraPos.Add(forward), mgl32.Vec3{0, 1, 0})
+
+	fov := float32(45.0 / camera.Zoom)
+	if fov < 5.0 {
+		fov = 5.0
+	}
+	if fov > 90.0 {
+		fov = 90.0
+	}
+	width, height := window.FramebufferSize()
+	aspectRatio := float32(width) / float32(max(height, 1))
+	projection := mgl32.Perspective(mgl32.DegToRad(fov), aspectRatio, 0.1, 10_000.0)
+
+	return view, projection
+}
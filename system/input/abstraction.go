@@ -20,6 +20,11 @@ const (
 	KeyLeftShift
 	KeyEqual
 	KeyMinus
+	KeyUp
+	KeyDown
+	KeyLeft
+	KeyRight
+	KeyB
 	// Add more keys as needed
 )
 
@@ -101,6 +101,11 @@ func (p *ImGuiProvider) updateKeyboardState(io *imgui.IO) {
 	p.inputState.keyStates[KeyLeftShift] = imgui.IsKeyDown(imgui.KeyLeftShift)
 	p.inputState.keyStates[KeyEqual] = imgui.IsKeyDown(imgui.KeyEqual)
 	p.inputState.keyStates[KeyMinus] = imgui.IsKeyDown(imgui.KeyMinus)
+	p.inputState.keyStates[KeyUp] = imgui.IsKeyDown(imgui.KeyUpArrow)
+	p.inputState.keyStates[KeyDown] = imgui.IsKeyDown(imgui.KeyDownArrow)
+	p.inputState.keyStates[KeyLeft] = imgui.IsKeyDown(imgui.KeyLeftArrow)
+	p.inputState.keyStates[KeyRight] = imgui.IsKeyDown(imgui.KeyRightArrow)
+	p.inputState.keyStates[KeyB] = imgui.IsKeyDown(imgui.KeyB)
 }
 
 // updateMouseState updates mouse state with error handling
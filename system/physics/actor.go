@@ -1,14 +1,98 @@
 package physics
 
 import (
+	"math"
 	"otto/system"
+	"slices"
 
 	"github.com/anthdm/hollywood/actor"
 	"github.com/go-gl/mathgl/mgl64"
 )
 
+const (
+	// FixedDeltaTime is the duration of a single simulation step. Every step uses
+	// the same dt so results do not depend on ticker jitter or scheduling.
+	FixedDeltaTime = 1.0 / 256
+
+	// maxSubsteps caps the steps taken for a single tick. After a long stall the
+	// remaining time is dropped instead of trying to catch up, which would only
+	// make the next tick slower.
+	maxSubsteps = 8
+)
+
 type Physics struct {
-	entities map[*actor.PID]EntityRigidBody
+	entities    map[*actor.PID]*body
+	bodies      []*body // Registration order, iterating a slice is much cheaper than a map
+	active      []*body // Dynamic and kinematic bodies, the only ones stepped every tick
+	modelBounds map[string]mgl64.Vec3
+	nextID      uint32
+
+	// Contact pairs touching during the last tick, keyed by the ids of both bodies
+	contacts     map[pairKey]*trackedContact
+	stepContacts []contact       // Scratch buffer for the contacts of a single step
+	stepPoints   []manifoldPoint // Scratch buffer for the solver state of their points
+
+	// Bodies overlapping a sensor during the last tick, keyed like contacts
+	triggers map[pairKey]*trackedTrigger
+
+	// Joints in creation order, jointPairs counts the joints between two bodies
+	// and broken holds the joints that broke since the last tick
+	joints     []*joint
+	jointPairs map[pairKey]int
+	stepJoints []*joint // Scratch buffer for the joints of a single step
+	broken     []brokenJoint
+
+	// Sleep state, the slices are scratch buffers reused by every step
+	touchedSleeping []*body
+	islandParents   []int32
+	islandAwake     []bool
+
+	// Fixed timestep state, the accumulator holds simulation time not yet stepped
+	accumulator float64
+	steps       uint64
+
+	// Broadphase proxies, owners is indexed by proxy id. Static and sleeping bodies
+	// live in a separate hash that is only queried by moving bodies, so they never
+	// pair up with each other and are never updated.
+	broadphase       *SpatialHash
+	owners           []*body
+	staticBroadphase *SpatialHash
+	staticOwners     []*body
+
+	// ground is found by every dynamic body: the floor plane at y=0, or the
+	// heightfield of terrain when the terrain body is registered
+	ground  *body
+	terrain *body
+
+	// World gravity, the bodies registered as force fields and those driven
+	// as vehicles
+	gravity  mgl64.Vec3
+	fields   []*body
+	vehicles []*body
+
+	// Workers share the independent parts of every step. Contacts are solved
+	// per region of the world, busyRegions holds the regions with contacts in
+	// the current step and crossContacts those left to the serial pass.
+	workers       *workerPool
+	regions       []*region
+	regionIndex   map[regionKey]int32
+	busyRegions   []*region
+	crossContacts []int32
+
+	// Scratch buffers of the contact search, the candidate pairs and the
+	// results of every batch of the narrowphase
+	stepPairs   [][2]*body
+	narrowphase []narrowphase
+
+	// Debug geometry selected by the renderer, rebuilt after every tick
+	debugDraw  DebugDraw
+	debugLines []DebugLine
+
+	// Deterministic mode queues commands until the next tick and hashes the
+	// state of every tick
+	deterministic bool
+	pending       []any
+	hashes        [stateHashHistory]stateHash
 }
 
 var _ actor.Receiver = (*Physics)(nil)
@@ -23,14 +107,50 @@ func (p *Physics) Receive(ctx *actor.Context) {
 	switch msg := ctx.Message().(type) {
 	case actor.Initialized:
 		ctx.Engine().Subscribe(ctx.PID())
-		p.entities = make(map[*actor.PID]EntityRigidBody)
+		p.init()
+	case actor.Stopped:
+		p.workers.stop()
+	case RequestRaycast:
+		ctx.Respond(RaycastResponse{
+			Hits: p.Raycast(msg.Origin, msg.Direction, msg.MaxDistance, msg.LayerMask, msg.All, msg.Ignore),
+		})
+	case RequestShapeCast:
+		ctx.Respond(ShapeCastResponse{
+			Hits: p.ShapeCast(msg.Origin, msg.Direction, msg.HalfExtents, msg.MaxDistance, msg.LayerMask, msg.All, msg.Ignore),
+		})
+	case RequestStateHash:
+		response := StateHashResponse{Tick: msg.Tick}
+		response.Hash, response.Found = p.TickStateHash(msg.Tick)
+		ctx.Respond(response)
+	case EventSetDebugDraw:
+		p.debugDraw = msg.Draw
+		p.debugLines = p.debugGeometry()
+	case RequestDebugGeometry:
+		ctx.Respond(DebugGeometryResponse{Step: p.steps, Lines: p.debugLines})
+	case system.ServerTick:
+		p.Update(ctx)
+	default:
+		if p.deterministic {
+			p.pending = append(p.pending, msg)
+			return
+		}
+		p.apply(msg)
+	}
+}
+
+// apply carries out a command sent to the physics system
+func (p *Physics) apply(msg any) {
+	switch msg := msg.(type) {
+	case EventModelBounds:
+		p.SetModelBounds(msg.ModelName, msg.Bounds)
 	case EventRigidBodyRegister:
-		p.entities[msg.PID] = msg.EntityRigidBody
+		p.Register(msg.PID, msg.EntityRigidBody)
 	case EventRigidBodyUpdate:
 		entity, ok := p.entities[msg.PID]
 		if !ok {
 			return
 		}
+		p.wake(entity)
 
 		// Update velocity components, preserving existing components when not provided
 		if msg.Velocity.X() != 0 {
@@ -45,9 +165,215 @@ func (p *Physics) Receive(ctx *actor.Context) {
 
 		// Update angular velocity
 		entity.AngularVelocity = msg.AngularVelocity
-		p.entities[msg.PID] = entity
-	case system.ServerTick:
-		p.Update(ctx)
+	case EventApplyForce:
+		if b, ok := p.entities[msg.PID]; ok {
+			p.wake(b)
+			b.force = b.force.Add(msg.Force)
+		}
+	case EventApplyImpulse:
+		if b, ok := p.entities[msg.PID]; ok {
+			p.wake(b)
+			b.Velocity = b.Velocity.Add(msg.Impulse.Mul(b.InverseMass))
+		}
+	case EventSetVelocity:
+		if b, ok := p.entities[msg.PID]; ok {
+			p.wake(b)
+			b.Velocity = msg.Velocity
+			b.AngularVelocity = msg.AngularVelocity
+		}
+	case EventCharacterMove:
+		if b, ok := p.entities[msg.PID]; ok && b.character != nil {
+			b.character.walk = mgl64.Vec3{msg.Velocity.X(), 0, msg.Velocity.Z()}
+			if msg.Jump {
+				b.character.sinceJump = 0
+			}
+		}
+	case EventVehicleInput:
+		if b, ok := p.entities[msg.PID]; ok && b.vehicle != nil {
+			p.wake(b)
+			b.vehicle.throttle = mgl64.Clamp(msg.Throttle, -1, 1)
+			b.vehicle.steering = mgl64.Clamp(msg.Steering, -1, 1)
+			b.vehicle.brake = mgl64.Clamp(msg.Brake, 0, 1)
+		}
+	case EventSetGravity:
+		p.SetGravity(msg.Gravity)
+	case EventJointCreate:
+		p.CreateJoint(msg.ID, msg.Joint)
+	case EventJointDestroy:
+		p.DestroyJoint(msg.ID)
+	}
+}
+
+// init prepares the empty physics world
+func (p *Physics) init() {
+	p.entities = make(map[*actor.PID]*body)
+	p.bodies = nil
+	p.active = nil
+	p.modelBounds = make(map[string]mgl64.Vec3)
+	p.nextID = 0
+	p.contacts = make(map[pairKey]*trackedContact)
+	p.triggers = make(map[pairKey]*trackedTrigger)
+	p.joints = nil
+	p.jointPairs = make(map[pairKey]int)
+	p.broken = nil
+	p.broadphase = NewSpatialHash(defaultCellSize)
+	p.owners = nil
+	p.staticBroadphase = NewSpatialHash(defaultCellSize)
+	p.staticOwners = nil
+	p.gravity = DefaultGravity
+	p.fields = nil
+	p.vehicles = nil
+	p.workers = newWorkerPool(0)
+	p.regions = nil
+	p.regionIndex = make(map[regionKey]int32)
+	p.busyRegions = nil
+
+	// The floor plane at y=0 behaves as a static body that is never registered
+	p.ground = &body{ground: true, material: groundMaterial}
+	p.ground.BodyType = BodyStatic
+	p.ground.Orientation = mgl64.QuatIdent()
+	p.ground.updateRotation()
+}
+
+// Register adds a body to the simulation, replacing it if the PID is already known
+func (p *Physics) Register(pid *actor.PID, entity EntityRigidBody) {
+	if b, ok := p.entities[pid]; ok {
+		p.wake(b)
+		p.removeProxy(b)
+		if b.BodyType != BodyStatic {
+			p.active = removeBody(p.active, b)
+		}
+		if b.ForceField != nil {
+			p.fields = removeBody(p.fields, b)
+		}
+		if b.vehicle != nil {
+			p.vehicles = removeBody(p.vehicles, b)
+		}
+		b.EntityRigidBody = entity
+		b.Orientation = normalizeOrientation(entity.Orientation)
+		b.halfExtents = p.halfExtents(entity)
+		b.setCharacter(entity.Character)
+		b.setForceField(entity.ForceField)
+		b.setVehicle(entity.Vehicle)
+		b.setShape(entity.Shape)
+		b.setMass(entity.Mass)
+		b.setMaterial(entity.Material)
+		b.updateInertia()
+		b.keepTransform()
+		p.insertProxy(b)
+		if b.BodyType != BodyStatic {
+			p.active = append(p.active, b)
+		}
+		if b.ForceField != nil {
+			p.fields = append(p.fields, b)
+			p.wakeInside(b)
+		}
+		if b.vehicle != nil {
+			p.vehicles = append(p.vehicles, b)
+		}
+		p.updateTerrain(b)
+		return
+	}
+
+	b := &body{
+		EntityRigidBody: entity,
+		pid:             pid,
+		id:              p.nextID,
+		halfExtents:     p.halfExtents(entity),
+	}
+	p.nextID++
+	b.Orientation = normalizeOrientation(entity.Orientation)
+	b.setCharacter(entity.Character)
+	b.setForceField(entity.ForceField)
+	b.setVehicle(entity.Vehicle)
+	b.setShape(entity.Shape)
+	b.setMass(entity.Mass)
+	b.setMaterial(entity.Material)
+	b.updateInertia()
+	b.keepTransform()
+	p.insertProxy(b)
+
+	p.entities[pid] = b
+	p.bodies = append(p.bodies, b)
+	if b.BodyType != BodyStatic {
+		p.active = append(p.active, b)
+	}
+	if b.ForceField != nil {
+		p.fields = append(p.fields, b)
+		p.wakeInside(b)
+	}
+	if b.vehicle != nil {
+		p.vehicles = append(p.vehicles, b)
+	}
+	p.updateTerrain(b)
+}
+
+// updateTerrain makes the heightfield of a body the ground of the world, or
+// brings back the floor plane when the terrain body stops being terrain. The
+// ground changing under them wakes every body up.
+func (p *Physics) updateTerrain(b *body) {
+	switch {
+	case b.shape.heightfield != nil:
+		p.terrain = b
+		p.ground.shape = b.shape
+		p.ground.Position = b.Position
+		p.ground.material = b.material
+	case p.terrain == b:
+		p.terrain = nil
+		p.ground.shape = collisionShape{}
+		p.ground.Position = mgl64.Vec3{}
+		p.ground.material = groundMaterial
+	default:
+		return
+	}
+
+	for _, other := range p.bodies {
+		p.wake(other)
+	}
+}
+
+// insertProxy adds a body to the broadphase matching its state
+func (p *Physics) insertProxy(b *body) {
+	hash, owners := p.broadphase, &p.owners
+	b.staticProxy = b.dormant()
+	if b.staticProxy {
+		hash, owners = p.staticBroadphase, &p.staticOwners
+	}
+
+	b.proxy = hash.Insert(b.bounds())
+	if int(b.proxy) >= len(*owners) {
+		*owners = append(*owners, make([]*body, int(b.proxy)-len(*owners)+1)...)
+	}
+	(*owners)[b.proxy] = b
+}
+
+// removeProxy removes a body from the broadphase it is stored in
+func (p *Physics) removeProxy(b *body) {
+	if b.staticProxy {
+		p.staticBroadphase.Remove(b.proxy)
+		p.staticOwners[b.proxy] = nil
+		return
+	}
+	p.broadphase.Remove(b.proxy)
+	p.owners[b.proxy] = nil
+}
+
+// SetModelBounds records the extents of a model and resizes the bodies using it
+func (p *Physics) SetModelBounds(modelName string, bounds mgl64.Vec3) {
+	p.modelBounds[modelName] = bounds
+	for _, b := range p.bodies {
+		if b.ModelName != modelName {
+			continue
+		}
+
+		b.halfExtents = p.halfExtents(b.EntityRigidBody)
+		b.setShape(b.Shape)
+		b.updateInertia()
+		if b.staticProxy {
+			p.staticBroadphase.Update(b.proxy, b.bounds())
+		} else {
+			p.broadphase.Update(b.proxy, b.bounds())
+		}
 	}
 }
 
@@ -57,70 +383,163 @@ func (p *Physics) Update(ctx *actor.Context) {
 		panic("tick message not found")
 	}
 
-	for pid, entity := range p.entities {
-		// Apply gravity to all entities
-		p.ApplyGravity(&entity, tick.DeltaTime)
-		p.entities[pid] = entity
+	if substeps := p.tick(tick.DeltaTime); substeps > 0 {
+		p.publish(ctx)
+		if p.debugDraw != 0 {
+			p.debugLines = p.debugGeometry()
+		}
+	}
+}
 
-		// Always update position if there's any velocity (including from gravity)
-		p.UpdatePosition(ctx, pid, entity, tick.DeltaTime)
+// tick runs the steps covered by a server tick. Deterministic mode ignores the
+// elapsed wall-clock time and takes exactly one step, after applying the
+// commands received since the last tick.
+func (p *Physics) tick(deltaTime float64) int {
+	if p.deterministic {
+		p.applyPending()
+		deltaTime = FixedDeltaTime
 	}
+	return p.Advance(deltaTime)
 }
 
-func (p *Physics) ApplyGravity(entity *EntityRigidBody, deltaTime float64) {
-	// Apply gravity acceleration (9.8 m/s², scaled for game world)
-	gravityAcceleration := -9.8 * 0.2 // Further reduced for gentler fall speed
-	gravityVelocity := mgl64.Vec3{0, gravityAcceleration * deltaTime, 0}
+// Advance adds elapsed wall-clock time to the accumulator and runs as many fixed
+// steps as it covers, up to maxSubsteps. It returns the number of steps taken.
+func (p *Physics) Advance(deltaTime float64) int {
+	p.accumulator += deltaTime
 
-	// Add gravity to existing velocity
-	entity.Velocity = entity.Velocity.Add(gravityVelocity)
-}
-
-func (p *Physics) UpdatePosition(ctx *actor.Context, pid *actor.PID, entity EntityRigidBody, deltaTime float64) {
-	// Apply velocity to position with frame-rate independent movement speed
-	movementSpeed := 7.0
-	newPosition := entity.Position.Add(entity.Velocity.Mul(movementSpeed * deltaTime))
-
-	// Calculate the bottom of the entity based on its scale
-	// For cubes and other entities, the bottom is at position.Y - (scale.Y / 2)
-	entityBottom := newPosition.Y() - (entity.Scale.Y() / 2)
-
-	// Floor collision detection at y=0
-	// Check if the bottom of the entity would go below the floor
-	if entityBottom < 0 {
-		// Calculate the correct position so the bottom of the entity is at y=0
-		correctedY := entity.Scale.Y() / 2
-		newPosition = mgl64.Vec3{newPosition.X(), correctedY, newPosition.Z()}
-
-		// Handle collision based on entity type
-		if entity.Velocity.Y() < 0 {
-			if entity.EntityType == "player" {
-				// Player should not bounce - just stop
-				entity.Velocity = mgl64.Vec3{entity.Velocity.X(), 0, entity.Velocity.Z()}
-			} else {
-				// Other entities (cubes) should bounce
-				bounceFactor := 0.3 // 30% bounce (70% energy loss)
-				entity.Velocity = mgl64.Vec3{entity.Velocity.X(), -entity.Velocity.Y() * bounceFactor, entity.Velocity.Z()}
+	substeps := 0
+	for p.accumulator >= FixedDeltaTime && substeps < maxSubsteps {
+		p.Step(FixedDeltaTime)
+		p.accumulator -= FixedDeltaTime
+		p.steps++
+		substeps++
+		if p.deterministic {
+			p.recordStateHash()
+		}
+	}
+
+	// Drop whole steps we could not afford, keeping the fractional part for interpolation
+	if p.accumulator >= FixedDeltaTime {
+		p.accumulator = math.Mod(p.accumulator, FixedDeltaTime)
+	}
+
+	// Forces sent during the last tick have now been integrated
+	if substeps > 0 {
+		for _, b := range p.active {
+			b.force = mgl64.Vec3{}
+		}
+	}
+
+	return substeps
+}
+
+// Step advances the simulation by deltaTime: bodies are integrated first and
+// any overlaps between them are resolved afterwards. Update always calls it
+// with FixedDeltaTime. Static and sleeping bodies are never visited. Free
+// bodies, candidate pairs and the contacts of every region are spread over the
+// workers, and all their results are merged before Step returns.
+func (p *Physics) Step(deltaTime float64) {
+	p.applyForceFields(deltaTime)
+	p.driveVehicles(deltaTime)
+
+	// Free bodies only depend on their own state while they are integrated
+	p.workers.run(len(p.active), p.workers.batch(len(p.active)), func(_, start, end int) {
+		for _, b := range p.active[start:end] {
+			// Fields and vehicles only change velocities, nothing moved yet
+			b.keepTransform()
+			b.grounded, b.floored = false, false
+			if b.character == nil && b.BodyType == BodyDynamic && !b.ContinuousCollision {
+				p.integrate(b, deltaTime)
+				b.floored = p.settleOnFloor(b)
 			}
 		}
+	})
+
+	for _, b := range p.active {
+		switch {
+		// Characters walk with their controller, sliding along what they run into
+		case b.character != nil:
+			p.moveCharacter(b, deltaTime)
+
+		// Kinematic bodies follow the velocity given by their owner and nothing else
+		case b.BodyType == BodyKinematic:
+			p.moveKinematic(b, deltaTime)
+
+		// Continuous collision looks for what the body hit along the way
+		case b.ContinuousCollision:
+			start := b.Position
+			p.integrate(b, deltaTime)
+			p.sweep(b, start)
+		}
+		p.broadphase.Update(b.proxy, b.bounds())
 	}
 
-	entity.Position = newPosition
+	contacts := p.findContacts()
+	p.wakeTouched()
+
+	joints := p.stepJoints[:0]
+	for _, j := range p.joints {
+		if p.simulated(j) {
+			joints = append(joints, j)
+		}
+	}
+	p.stepJoints = joints
 
-	// Apply angular velocity to rotation with frame-rate independent rotation speed
-	rotationSpeed := 4.0 // Increased for faster camera
-	entity.Rotation = entity.Rotation.Add(entity.AngularVelocity.Mul(rotationSpeed * deltaTime))
+	p.solveContacts(contacts, joints, deltaTime)
+
+	p.recordContacts(contacts)
+	p.updateSleep(contacts, deltaTime)
+}
+
+// integrate applies gravity and accumulated forces to a dynamic body and moves
+// it by its velocity
+func (p *Physics) integrate(b *body, deltaTime float64) {
+	p.ApplyGravity(&b.EntityRigidBody, deltaTime)
+	if b.force != (mgl64.Vec3{}) {
+		p.ApplyForce(&b.EntityRigidBody, b.force, deltaTime)
+	}
+	p.updatePosition(b, deltaTime)
+}
+
+// ApplyGravity accelerates an entity by the world gravity times its gravity scale
+func (p *Physics) ApplyGravity(entity *EntityRigidBody, deltaTime float64) {
+	scale := 1.0
+	if entity.GravityScale != nil {
+		scale = *entity.GravityScale
+	}
+
+	// Add gravity to existing velocity
+	for i := 0; i < 3; i++ {
+		entity.Velocity[i] += p.gravity[i] * scale * deltaTime
+	}
+}
+
+// ApplyForce integrates a force into the velocity of an entity, scaled by its inverse
+// mass so that heavier bodies accelerate less than lighter ones
+func (p *Physics) ApplyForce(entity *EntityRigidBody, force mgl64.Vec3, deltaTime float64) {
+	entity.Velocity = entity.Velocity.Add(force.Mul(entity.InverseMass * deltaTime))
+}
+
+func (p *Physics) updatePosition(b *body, deltaTime float64) {
+	entity := &b.EntityRigidBody
+
+	// Apply velocity to position, contacts with the floor and other bodies are resolved afterwards.
+	// Like in the solver the components are handled one by one, see solveLinear.
+	for i := 0; i < 3; i++ {
+		entity.Position[i] += entity.Velocity[i] * deltaTime
+	}
 
 	// Apply damping to horizontal velocity only when there's no input
-	// Allow gravity to continue acting (vertical velocity)
-	horizontalVelocity := mgl64.Vec3{entity.Velocity.X(), 0, entity.Velocity.Z()}
-	if horizontalVelocity.Len() < 0.01 {
+	// Allow gravity to continue acting (vertical velocity). Vehicles pull away
+	// slower than this from a standstill, their tires stop them instead.
+	x, z := entity.Velocity.X(), entity.Velocity.Z()
+	if math.Sqrt(x*x+z*z) < 0.07 && b.vehicle == nil {
 		// Only damp horizontal velocity, preserve vertical velocity from gravity
 		entity.Velocity = mgl64.Vec3{0, entity.Velocity.Y(), 0} // Keep only vertical velocity
 	} else {
-		// When there's horizontal movement, apply gentle damping ONLY to horizontal components
+		// When there's horizontal movement, apply the material damping ONLY to horizontal components
 		// NEVER touch the vertical velocity (gravity)
-		dampingFactor := 0.95 // 5% damping per frame
+		dampingFactor := math.Exp(-b.material.LinearDamping * deltaTime)
 		entity.Velocity = mgl64.Vec3{
 			entity.Velocity.X() * dampingFactor,
 			entity.Velocity.Y(), // Keep gravity completely unaffected
@@ -128,28 +547,68 @@ func (p *Physics) UpdatePosition(ctx *actor.Context, pid *actor.PID, entity Enti
 		}
 	}
 
-	// Apply damping to angular velocity when there's no rotation input
+	// Apply damping to angular velocity, stopping it when it becomes negligible.
+	// This happens before rotating so that resting bodies stay exactly aligned.
+	if entity.AngularVelocity == (mgl64.Vec3{}) {
+		return
+	}
+	entity.AngularVelocity = entity.AngularVelocity.Mul(math.Exp(-b.material.AngularDamping * deltaTime))
 	if entity.AngularVelocity.Len() < 0.01 {
 		entity.AngularVelocity = mgl64.Vec3{}
 	}
+	b.integrateOrientation(deltaTime)
+}
 
-	p.entities[pid] = entity
-
-	ctx.Send(pid, EventRigidBodyTransform{
-		PID:      pid,
-		Position: entity.Position,
-		Rotation: entity.Rotation,
-	})
+// moveKinematic moves a kinematic body by its velocity, without gravity, damping
+// or the floor getting in the way
+func (p *Physics) moveKinematic(b *body, deltaTime float64) {
+	b.Position = b.Position.Add(b.Velocity.Mul(deltaTime))
+	b.integrateOrientation(deltaTime)
+}
 
-	// Send ground state for player entities
-	if entity.EntityType == "player" {
-		// Check if player is on ground (bottom of entity is at floor level)
-		entityBottom := entity.Position.Y() - (entity.Scale.Y() / 2)
-		isOnGround := entityBottom <= 0.01 && entity.Velocity.Y() <= 0.01
+// publish sends the simulated transforms back to the entity actors. Static and
+// sleeping bodies do not move, so their owners already know where they are.
+func (p *Physics) publish(ctx *actor.Context) {
+	alpha := p.accumulator / FixedDeltaTime
+	for _, b := range p.active {
+		ctx.Send(b.pid, EventRigidBodyTransform{
+			PID:         b.pid,
+			Position:    b.Position,
+			Orientation: b.Orientation,
+			Step:        p.steps,
+			Alpha:       alpha,
 
-		ctx.Send(pid, EventGroundState{
-			PID:        pid,
-			IsOnGround: isOnGround,
+			PreviousPosition:    b.previousPosition,
+			PreviousOrientation: b.previousOrientation,
 		})
+
+		// Characters are told what they stand on
+		if c := b.character; c != nil {
+			state := EventGroundState{
+				PID:        b.pid,
+				IsOnGround: c.onGround,
+				Normal:     c.groundNormal,
+			}
+			if c.groundBody != nil {
+				state.Ground = c.groundBody.pid
+			}
+			ctx.Send(b.pid, state)
+		}
+
+		// Vehicles are told where their wheels are
+		if v := b.vehicle; v != nil {
+			ctx.Send(b.pid, EventVehicleState{
+				PID:    b.pid,
+				Speed:  b.Velocity.Dot(b.rotation.Col(2)),
+				Wheels: slices.Clone(v.wheels),
+			})
+		}
+	}
+
+	send := func(pid *actor.PID, event any) {
+		ctx.Send(pid, event)
 	}
+	p.contactEvents(send)
+	p.triggerEvents(send)
+	p.jointEvents(send)
 }
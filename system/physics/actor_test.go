@@ -0,0 +1,118 @@
+package physics
+
+import (
+	"math"
+	"testing"
+
+	"github.com/anthdm/hollywood/actor"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+func TestAdvanceStepsAtFixedRate(t *testing.T) {
+	p := newTestPhysics()
+
+	// Jittery ticks averaging out to 10 steps
+	ticks := []float64{0.5, 1.7, 0.8, 1.3, 0.9, 1.1, 2.0, 0.2, 1.0, 0.5}
+	total := 0
+	for _, tick := range ticks {
+		total += p.Advance(tick * FixedDeltaTime)
+	}
+
+	if total != 10 {
+		t.Errorf("expected 10 fixed steps, got %d", total)
+	}
+	if p.accumulator < 0 || p.accumulator >= FixedDeltaTime {
+		t.Errorf("accumulator should hold less than one step, got %f", p.accumulator)
+	}
+}
+
+func TestAdvanceCapsSubsteps(t *testing.T) {
+	p := newTestPhysics()
+
+	// A one second stall must not run 256 steps in a single tick
+	if steps := p.Advance(1.0); steps != maxSubsteps {
+		t.Errorf("expected %d substeps, got %d", maxSubsteps, steps)
+	}
+	if p.accumulator >= FixedDeltaTime {
+		t.Errorf("excess time should be dropped, accumulator is %f", p.accumulator)
+	}
+}
+
+func TestForcesScaleWithMassAndClearAfterTick(t *testing.T) {
+	p := newTestPhysics()
+
+	light := actor.NewPID("local", "light")
+	heavy := actor.NewPID("local", "heavy")
+	p.Register(light, EntityRigidBody{Position: mgl64.Vec3{0, 10, 0}, Scale: mgl64.Vec3{1, 1, 1}, Mass: 1})
+	p.Register(heavy, EntityRigidBody{Position: mgl64.Vec3{5, 10, 0}, Scale: mgl64.Vec3{1, 1, 1}, Mass: 4})
+
+	force := mgl64.Vec3{0, 0, 800}
+	p.entities[light].force = force
+	p.entities[heavy].force = force
+	p.Advance(FixedDeltaTime)
+
+	lightSpeed := p.entities[light].Velocity.Z()
+	heavySpeed := p.entities[heavy].Velocity.Z()
+	if math.Abs(lightSpeed-4*heavySpeed) > 1e-9 {
+		t.Errorf("a body four times heavier should gain a quarter of the speed, got %f and %f", lightSpeed, heavySpeed)
+	}
+	if p.entities[light].force != (mgl64.Vec3{}) {
+		t.Error("forces should be cleared once the tick has been stepped")
+	}
+}
+
+func TestMessagesUseWorldUnitsPerSecond(t *testing.T) {
+	p := newTestPhysics()
+	p.SetGravity(mgl64.Vec3{})
+
+	// Vertical velocities are not damped, so a second of steps moves a body by its velocity
+	moving, pushed, forced := actor.NewPID("local", "moving"), actor.NewPID("local", "pushed"), actor.NewPID("local", "forced")
+	p.Register(moving, EntityRigidBody{Position: mgl64.Vec3{0, 10, 0}, Scale: mgl64.Vec3{1, 1, 1}})
+	p.Register(pushed, EntityRigidBody{Position: mgl64.Vec3{5, 10, 0}, Scale: mgl64.Vec3{1, 1, 1}, Mass: 2})
+	p.Register(forced, EntityRigidBody{Position: mgl64.Vec3{10, 10, 0}, Scale: mgl64.Vec3{1, 1, 1}, Mass: 2})
+	walker := registerCharacter(p, 20)
+
+	p.apply(EventSetVelocity{PID: moving, Velocity: mgl64.Vec3{0, 2, 0}})
+	p.apply(EventApplyImpulse{PID: pushed, Impulse: mgl64.Vec3{0, 4, 0}})
+	p.apply(EventApplyForce{PID: forced, Force: mgl64.Vec3{0, 512, 0}})
+	p.apply(EventCharacterMove{PID: walker.pid, Velocity: mgl64.Vec3{3, 0, 0}})
+
+	// The force is only applied during the first step, for 1/256 of a second
+	p.Advance(FixedDeltaTime)
+	for i := 1; i < 256; i++ {
+		p.Step(FixedDeltaTime)
+	}
+
+	tests := []struct {
+		name     string
+		got      float64
+		expected float64
+	}{
+		{"velocity of 2", p.entities[moving].Position.Y() - 10, 2},
+		{"impulse of 4 on a mass of 2", p.entities[pushed].Position.Y() - 10, 2},
+		{"force of 512 on a mass of 2 during one step", p.entities[forced].Position.Y() - 10, 1},
+		{"character walking at 3", walker.Position.X() - 20, 3},
+	}
+	for _, tt := range tests {
+		if math.Abs(tt.got-tt.expected) > 1e-9 {
+			t.Errorf("%s: moved %f units in a second, expected %f", tt.name, tt.got, tt.expected)
+		}
+	}
+}
+
+func TestPreviousTransformIsOneStepBack(t *testing.T) {
+	p := newTestPhysics()
+	p.SetGravity(mgl64.Vec3{})
+
+	rising := actor.NewPID("local", "rising")
+	p.Register(rising, EntityRigidBody{Position: mgl64.Vec3{0, 10, 0}, Velocity: mgl64.Vec3{0, 2, 0}, Scale: mgl64.Vec3{1, 1, 1}})
+
+	// Interpolation blends over one fixed step even when a tick runs several
+	if steps := p.Advance(3.5 * FixedDeltaTime); steps != 3 {
+		t.Fatalf("expected 3 steps, got %d", steps)
+	}
+	b := p.entities[rising]
+	if moved := b.Position.Sub(b.previousPosition); math.Abs(moved.Y()-2*FixedDeltaTime) > 1e-12 || moved.X() != 0 || moved.Z() != 0 {
+		t.Errorf("expected the previous transform one step of %f back, got %v", 2*FixedDeltaTime, moved)
+	}
+}
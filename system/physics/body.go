@@ -0,0 +1,166 @@
+package physics
+
+import (
+	"github.com/anthdm/hollywood/actor"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// body is the simulation state kept for every registered entity
+type body struct {
+	EntityRigidBody
+
+	pid         *actor.PID
+	id          uint32     // Registration order, used to key contact pairs
+	proxy       int32      // Broadphase proxy id
+	halfExtents mgl64.Vec3 // Cached collision half extents
+	grounded    bool       // Resting on the floor or on another body this step
+	region      int32      // Region solving the contacts of the body this step
+	contacts    int32      // Contacts of the body this step, counted for moving bodies only
+	touching    bool       // Touched another body or was held by a joint during the last step
+	floored     bool       // Settled on the floor plane while integrated, see settleOnFloor
+	force       mgl64.Vec3 // Forces accumulated since the last tick
+	material    PhysicsMaterial
+	staticProxy bool // Stored in the static broadphase, either static or sleeping
+	ground      bool // The floor plane at y=0 rather than a registered entity
+
+	// Transform before the latest step, published so that rendering can blend
+	// over exactly one fixed step
+	previousPosition    mgl64.Vec3
+	previousOrientation mgl64.Quat
+
+	// Rotation state derived from the orientation, refreshed by updateRotation
+	rotation            mgl64.Mat3
+	axisAligned         bool       // Not rotated, so the cheaper box tests apply
+	inverseInertia      mgl64.Vec3 // Diagonal of the inverse inertia tensor in local space
+	inverseInertiaWorld mgl64.Mat3
+
+	// Sleep state, island holds the bodies that fell asleep together
+	sleeping    bool
+	sleepTimer  float64
+	island      []*body
+	islandIndex int32 // Index in the union-find of the current step
+
+	character *character // Set for bodies moved by the character controller
+	vehicle   *vehicle   // Set for bodies driven on raycast wheels
+	shape     collisionShape
+}
+
+// keepTransform remembers the current transform as the one before the next step
+func (b *body) keepTransform() {
+	b.previousPosition, b.previousOrientation = b.Position, b.Orientation
+}
+
+// setMass updates the mass of a body together with its inverse. Static and
+// kinematic bodies behave as if infinitely heavy, so their inverse mass is zero.
+func (b *body) setMass(mass float64) {
+	if mass <= 0 {
+		mass = defaultMass
+	}
+	b.Mass = mass
+	b.InverseMass = 1 / mass
+	if b.BodyType != BodyDynamic {
+		b.InverseMass = 0
+	}
+}
+
+// updateInertia derives the inertia tensor of a body from its collision box.
+// Bodies that cannot be rotated by contacts get a zero inverse inertia.
+func (b *body) updateInertia() {
+	defer b.updateRotation()
+
+	b.inverseInertia = mgl64.Vec3{}
+	if b.InverseMass == 0 || b.FixedRotation || b.updateShapeInertia() {
+		return
+	}
+
+	// Solid box: I = m/12 * (height² + depth²) with full extents, so m/3 with half extents
+	x, y, z := b.halfExtents.X(), b.halfExtents.Y(), b.halfExtents.Z()
+	inertia := mgl64.Vec3{
+		b.Mass / 3 * (y*y + z*z),
+		b.Mass / 3 * (x*x + z*z),
+		b.Mass / 3 * (x*x + y*y),
+	}
+	for axis := range inertia {
+		if inertia[axis] > 0 {
+			b.inverseInertia[axis] = 1 / inertia[axis]
+		}
+	}
+}
+
+// updateRotation refreshes the rotation matrix and world space inverse inertia
+// of a body after its orientation changed
+func (b *body) updateRotation() {
+	b.rotation = b.Orientation.Mat4().Mat3()
+	b.axisAligned = b.Orientation == mgl64.QuatIdent()
+	b.inverseInertiaWorld = b.rotation.Mul3(mgl64.Diag3(b.inverseInertia)).Mul3(b.rotation.Transpose())
+}
+
+// integrateOrientation rotates a body by its angular velocity over deltaTime
+func (b *body) integrateOrientation(deltaTime float64) {
+	if b.AngularVelocity == (mgl64.Vec3{}) {
+		return
+	}
+
+	spin := mgl64.Quat{W: 0, V: b.AngularVelocity}.Mul(b.Orientation).Scale(0.5 * deltaTime)
+	b.Orientation = b.Orientation.Add(spin).Normalize()
+	b.updateRotation()
+}
+
+// velocityAt returns the velocity of the point of a body at offset r from its center
+func (b *body) velocityAt(r mgl64.Vec3) mgl64.Vec3 {
+	return b.Velocity.Add(b.AngularVelocity.Cross(r))
+}
+
+// applyImpulse changes the linear and angular velocity of a body as if the
+// impulse was applied at offset r from its center
+func (b *body) applyImpulse(impulse, r mgl64.Vec3) {
+	b.Velocity = b.Velocity.Add(impulse.Mul(b.InverseMass))
+	b.AngularVelocity = b.AngularVelocity.Add(b.inverseInertiaWorld.Mul3x1(r.Cross(impulse)))
+}
+
+// setMaterial updates the material of a body, falling back to DefaultMaterial
+func (b *body) setMaterial(material *PhysicsMaterial) {
+	if material == nil {
+		b.material = DefaultMaterial
+		return
+	}
+	b.material = *material
+}
+
+// layer returns the layer bit of a body
+func (b *body) layer() uint32 {
+	if b.Layer == 0 {
+		return LayerDefault
+	}
+	return b.Layer
+}
+
+// interacts reports whether two bodies collide with or detect each other, which
+// needs the layer of each body to be in the collision mask of the other
+func (b *body) interacts(other *body) bool {
+	return b.layer()&other.collisionMask() != 0 && other.layer()&b.collisionMask() != 0
+}
+
+// collisionMask returns the layers a body collides with
+func (b *body) collisionMask() uint32 {
+	if b.CollisionMask == 0 {
+		return ^uint32(0)
+	}
+	return b.CollisionMask
+}
+
+// dormant reports whether a body is left out of the simulation, so that the
+// contacts it had when it stopped moving still hold
+func (b *body) dormant() bool {
+	return b.sleeping || b.BodyType == BodyStatic
+}
+
+// removeBody removes a body from a slice, preserving the order of the others
+func removeBody(bodies []*body, b *body) []*body {
+	for i, other := range bodies {
+		if other == b {
+			return append(bodies[:i], bodies[i+1:]...)
+		}
+	}
+	return bodies
+}
@@ -0,0 +1,240 @@
+package physics
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+const (
+	// maxManifoldPoints bounds the contact points kept for a pair of boxes
+	maxManifoldPoints = 8
+
+	// manifoldTolerance is how far outside of a box a corner may be and still
+	// count as touching it, so resting faces keep all of their corners in contact
+	manifoldTolerance = 0.02
+
+	// edgeAxisBias makes the separating axis test prefer face normals over edge
+	// cross products of nearly the same depth, which keeps resting contacts stable
+	edgeAxisBias = 0.95
+)
+
+// orientedBox is the collision box of a body in world space
+type orientedBox struct {
+	center mgl64.Vec3
+	axes   [3]mgl64.Vec3 // Local X, Y and Z axes in world space
+	half   mgl64.Vec3
+}
+
+// box returns the oriented collision box of a body
+func (b *body) box() orientedBox {
+	return orientedBox{
+		center: b.Position,
+		axes:   [3]mgl64.Vec3{b.rotation.Col(0), b.rotation.Col(1), b.rotation.Col(2)},
+		half:   b.halfExtents,
+	}
+}
+
+// worldExtents returns the half extents of the axis-aligned box around a body
+func (b *body) worldExtents() mgl64.Vec3 {
+	if b.shape.kind != ShapeBox {
+		return b.shapeExtents()
+	}
+	if b.axisAligned {
+		return b.halfExtents
+	}
+
+	var extents mgl64.Vec3
+	for row := 0; row < 3; row++ {
+		for col := 0; col < 3; col++ {
+			extents[row] += math.Abs(b.rotation.At(row, col)) * b.halfExtents[col]
+		}
+	}
+	return extents
+}
+
+// radius returns the half length of the projection of the box onto a unit axis
+func (o orientedBox) radius(axis mgl64.Vec3) float64 {
+	return o.half[0]*math.Abs(o.axes[0].Dot(axis)) +
+		o.half[1]*math.Abs(o.axes[1].Dot(axis)) +
+		o.half[2]*math.Abs(o.axes[2].Dot(axis))
+}
+
+// corners returns the eight corners of the box
+func (o orientedBox) corners() [8]mgl64.Vec3 {
+	var corners [8]mgl64.Vec3
+	for i := range corners {
+		corner := o.center
+		for axis := 0; axis < 3; axis++ {
+			offset := o.axes[axis].Mul(o.half[axis])
+			if i&(1<<axis) != 0 {
+				corner = corner.Add(offset)
+			} else {
+				corner = corner.Sub(offset)
+			}
+		}
+		corners[i] = corner
+	}
+	return corners
+}
+
+// contains reports whether a point lies inside the box grown by tolerance
+func (o orientedBox) contains(point mgl64.Vec3, tolerance float64) bool {
+	delta := point.Sub(o.center)
+	for axis := 0; axis < 3; axis++ {
+		if math.Abs(delta.Dot(o.axes[axis])) > o.half[axis]+tolerance {
+			return false
+		}
+	}
+	return true
+}
+
+// closestPoint returns the point of the box closest to a point
+func (o orientedBox) closestPoint(point mgl64.Vec3) mgl64.Vec3 {
+	delta := point.Sub(o.center)
+	closest := o.center
+	for axis := 0; axis < 3; axis++ {
+		distance := math.Max(-o.half[axis], math.Min(o.half[axis], delta.Dot(o.axes[axis])))
+		closest = closest.Add(o.axes[axis].Mul(distance))
+	}
+	return closest
+}
+
+// support returns the corner of the box furthest along a direction
+func (o orientedBox) support(direction mgl64.Vec3) mgl64.Vec3 {
+	point := o.center
+	for axis := 0; axis < 3; axis++ {
+		offset := o.axes[axis].Mul(o.half[axis])
+		if o.axes[axis].Dot(direction) < 0 {
+			point = point.Sub(offset)
+		} else {
+			point = point.Add(offset)
+		}
+	}
+	return point
+}
+
+// intersectRay returns the distance along a normalized ray to the box and the
+// surface normal where the ray enters it, by testing the ray in box space
+func (o orientedBox) intersectRay(origin, direction mgl64.Vec3) (float64, mgl64.Vec3, bool) {
+	delta := origin.Sub(o.center)
+	localOrigin := mgl64.Vec3{delta.Dot(o.axes[0]), delta.Dot(o.axes[1]), delta.Dot(o.axes[2])}
+	localDirection := mgl64.Vec3{direction.Dot(o.axes[0]), direction.Dot(o.axes[1]), direction.Dot(o.axes[2])}
+
+	distance, localNormal, ok := AABB{Min: o.half.Mul(-1), Max: o.half}.intersectRay(localOrigin, localDirection)
+	if !ok {
+		return 0, mgl64.Vec3{}, false
+	}
+	if distance == 0 {
+		return 0, direction.Mul(-1), true
+	}
+
+	normal := o.axes[0].Mul(localNormal[0]).Add(o.axes[1].Mul(localNormal[1])).Add(o.axes[2].Mul(localNormal[2]))
+	return distance, normal, true
+}
+
+// orientedSeparation finds the overlap between two rotated boxes with the
+// separating axis test: the face normals of both boxes and the cross products
+// of their edges. The normal is the axis of least penetration.
+func orientedSeparation(a, b *body) (mgl64.Vec3, float64, bool) {
+	boxA, boxB := a.box(), b.box()
+	delta := b.Position.Sub(a.Position)
+
+	var normal mgl64.Vec3
+	depth := math.Inf(1)
+	test := func(axis mgl64.Vec3, bias float64) bool {
+		length := axis.Len()
+		if length < 1e-9 {
+			// Parallel edges, the face axes already cover this direction
+			return true
+		}
+		axis = axis.Mul(1 / length)
+
+		distance := delta.Dot(axis)
+		overlap := boxA.radius(axis) + boxB.radius(axis) - math.Abs(distance)
+		if overlap <= 0 {
+			return false
+		}
+
+		if overlap < depth*bias {
+			depth = overlap
+			normal = axis
+			if distance < 0 {
+				normal = axis.Mul(-1)
+			}
+		}
+		return true
+	}
+
+	for i := 0; i < 3; i++ {
+		if !test(boxA.axes[i], 1) || !test(boxB.axes[i], 1) {
+			return mgl64.Vec3{}, 0, false
+		}
+	}
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			if !test(boxA.axes[i].Cross(boxB.axes[j]), edgeAxisBias) {
+				return mgl64.Vec3{}, 0, false
+			}
+		}
+	}
+
+	return normal, depth, true
+}
+
+// groundSeparation finds how deep a body sinks into the ground: the terrain
+// when the world has one, the floor plane at y=0 otherwise
+func groundSeparation(b, ground *body) (mgl64.Vec3, float64, bool) {
+	if ground.shape.heightfield != nil {
+		return terrainSeparation(b, ground)
+	}
+
+	lowest := b.Position.Y() - b.worldExtents().Y()
+	if b.shape.kind != ShapeBox {
+		shape := b.convex()
+		lowest = shape.support(mgl64.Vec3{0, -1, 0}).Y()
+	}
+	if lowest >= 0 {
+		return mgl64.Vec3{}, 0, false
+	}
+	return mgl64.Vec3{0, -1, 0}, -lowest, true
+}
+
+// addLowestCorners uses the corners of the body closest to the ground
+func (c *contact) addLowestCorners() {
+	corners := c.a.box().corners()
+	var heights [8]float64
+	lowest := math.Inf(1)
+	for i, corner := range corners {
+		ground, _ := c.b.groundAt(corner)
+		heights[i] = corner.Y() - ground
+		lowest = math.Min(lowest, heights[i])
+	}
+
+	for i, corner := range corners {
+		if heights[i] < lowest+manifoldTolerance {
+			c.addPoint(corner)
+		}
+	}
+}
+
+// addTouchingCorners uses the corners of each box touching the other box
+func (c *contact) addTouchingCorners() {
+	boxA, boxB := c.a.box(), c.b.box()
+	for _, corner := range boxB.corners() {
+		if c.pointCount < maxManifoldPoints && boxA.contains(corner, manifoldTolerance) {
+			c.addPoint(corner)
+		}
+	}
+	for _, corner := range boxA.corners() {
+		if c.pointCount < maxManifoldPoints && boxB.contains(corner, manifoldTolerance) {
+			c.addPoint(corner)
+		}
+	}
+
+	// Crossing edges have no corner inside the other box, so use the point
+	// halfway between the deepest points of both boxes
+	if c.pointCount == 0 {
+		c.addPoint(boxA.support(c.normal).Add(boxB.support(c.normal.Mul(-1))).Mul(0.5))
+	}
+}
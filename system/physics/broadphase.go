@@ -0,0 +1,381 @@
+package physics
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+const (
+	// defaultCellSize matches the unit cubes used across the playground, so
+	// neighbouring cubes rarely share a cell
+	defaultCellSize = 1.0
+
+	// maxProxyCells is the number of cells a body may cover before it is stored
+	// outside the grid. Huge bodies such as the floor would otherwise occupy
+	// millions of cells.
+	maxProxyCells = 64
+)
+
+// cellCoordinateLimit bounds cell coordinates so that they fit the packed
+// 21 bits per axis used as hash keys
+const cellCoordinateLimit = 1<<20 - 1
+
+// cellKey identifies a single cell of the spatial hash
+type cellKey struct {
+	x, y, z int32
+}
+
+// pack encodes the cell as a single integer, which hashes much faster than a struct
+func (c cellKey) pack() uint64 {
+	const bias = 1 << 20
+	return uint64(c.x+bias)<<42 | uint64(c.y+bias)<<21 | uint64(c.z+bias)
+}
+
+// proxy is the broadphase representation of a body
+type proxy struct {
+	aabb     AABB
+	min, max cellKey // Inclusive range of cells covered by the AABB
+	large    bool    // Stored in the large list instead of the grid
+	inUse    bool
+}
+
+// cell holds the proxies overlapping one cell of the grid
+type cell struct {
+	key uint64
+	ids []int32
+}
+
+// SpatialHash is a uniform grid broadphase. Every body is stored in each cell its
+// AABB covers, so bodies only need to be tested against the bodies sharing a cell.
+// Occupied cells are kept in a dense slice so that generating pairs is a linear
+// scan, the map is only used to find the cell for a key.
+type SpatialHash struct {
+	cellSize float64
+	cells    []cell
+	index    map[uint64]int32
+	proxies  []proxy
+	free     []int32
+	large    []int32
+
+	// Range of cells holding grid proxies, grown as proxies are placed and
+	// recomputed when it is needed after a cell was removed
+	occupiedMin, occupiedMax cellKey
+	occupiedValid            bool
+}
+
+// NewSpatialHash creates an empty spatial hash with the given cell size
+func NewSpatialHash(cellSize float64) *SpatialHash {
+	return &SpatialHash{
+		cellSize: cellSize,
+		index:    make(map[uint64]int32),
+	}
+}
+
+// Insert adds a new proxy for the given AABB and returns its id
+func (h *SpatialHash) Insert(aabb AABB) int32 {
+	var id int32
+	if n := len(h.free); n > 0 {
+		id = h.free[n-1]
+		h.free = h.free[:n-1]
+	} else {
+		id = int32(len(h.proxies))
+		h.proxies = append(h.proxies, proxy{})
+	}
+
+	h.proxies[id] = proxy{inUse: true}
+	h.place(id, aabb)
+	return id
+}
+
+// Update moves a proxy to a new AABB. Cells are only touched when the body
+// crosses into a different set of cells, which is rare for small movements.
+func (h *SpatialHash) Update(id int32, aabb AABB) {
+	p := &h.proxies[id]
+	lo, hi := h.cellRange(aabb)
+	if lo == p.min && hi == p.max {
+		p.aabb = aabb
+		return
+	}
+
+	h.unplace(id)
+	h.place(id, aabb)
+}
+
+// Remove deletes a proxy from the hash, its id may be reused by a later insert
+func (h *SpatialHash) Remove(id int32) {
+	h.unplace(id)
+	h.proxies[id] = proxy{}
+	h.free = append(h.free, id)
+}
+
+// AABB returns the bounds a proxy was last updated with
+func (h *SpatialHash) AABB(id int32) AABB {
+	return h.proxies[id].aabb
+}
+
+// Pairs calls fn once for every pair of proxies whose AABBs overlap
+func (h *SpatialHash) Pairs(fn func(a, b int32)) {
+	for _, c := range h.cells {
+		ids := c.ids
+		if len(ids) < 2 {
+			continue
+		}
+
+		for i, a := range ids {
+			pa := &h.proxies[a]
+			for _, b := range ids[i+1:] {
+				pb := &h.proxies[b]
+				if !overlaps(&pa.aabb, &pb.aabb) {
+					continue
+				}
+
+				// Bodies sharing several cells are only reported from the first
+				// cell they share, so each pair is emitted exactly once
+				if c.key != maxCell(pa.min, pb.min).pack() {
+					continue
+				}
+
+				fn(a, b)
+			}
+		}
+	}
+
+	// Large proxies are tested against everything else directly
+	for i, a := range h.large {
+		pa := &h.proxies[a]
+		for id := range h.proxies {
+			pb := &h.proxies[id]
+			if pb.inUse && !pb.large && pa.aabb.Overlaps(pb.aabb) {
+				fn(a, int32(id))
+			}
+		}
+		for _, b := range h.large[i+1:] {
+			if pa.aabb.Overlaps(h.proxies[b].aabb) {
+				fn(a, b)
+			}
+		}
+	}
+}
+
+// Query calls fn for every proxy whose AABB overlaps the given AABB
+func (h *SpatialHash) Query(aabb AABB, fn func(id int32)) {
+	if h.empty() {
+		return
+	}
+
+	h.queryGrid(aabb, fn)
+	h.queryLarge(aabb, fn)
+}
+
+// queryGrid calls fn for every proxy stored in the grid whose AABB overlaps the
+// given AABB, large proxies are left to queryLarge
+func (h *SpatialHash) queryGrid(aabb AABB, fn func(id int32)) {
+	if len(h.cells) == 0 {
+		return
+	}
+
+	lo, hi := h.cellRange(aabb)
+	for x := lo.x; x <= hi.x; x++ {
+		for y := lo.y; y <= hi.y; y++ {
+			for z := lo.z; z <= hi.z; z++ {
+				key := cellKey{x, y, z}
+				i, ok := h.index[key.pack()]
+				if !ok {
+					continue
+				}
+
+				for _, id := range h.cells[i].ids {
+					p := &h.proxies[id]
+					// Only report proxies from the first cell they share with the query
+					if key != maxCell(lo, p.min) {
+						continue
+					}
+					if p.aabb.Overlaps(aabb) {
+						fn(id)
+					}
+				}
+			}
+		}
+	}
+}
+
+// queryLarge calls fn for every large proxy whose AABB overlaps the given AABB
+func (h *SpatialHash) queryLarge(aabb AABB, fn func(id int32)) {
+	for _, id := range h.large {
+		if h.proxies[id].aabb.Overlaps(aabb) {
+			fn(id)
+		}
+	}
+}
+
+// occupied returns the bounds of the cells holding grid proxies, or false when
+// the grid is empty. Large proxies are not part of the bounds.
+func (h *SpatialHash) occupied() (AABB, bool) {
+	if len(h.cells) == 0 {
+		return AABB{}, false
+	}
+
+	if !h.occupiedValid {
+		first := true
+		for i := range h.proxies {
+			p := &h.proxies[i]
+			if !p.inUse || p.large {
+				continue
+			}
+			if first {
+				h.occupiedMin, h.occupiedMax = p.min, p.max
+				first = false
+				continue
+			}
+			h.occupiedMin = minCell(h.occupiedMin, p.min)
+			h.occupiedMax = maxCell(h.occupiedMax, p.max)
+		}
+		h.occupiedValid = true
+	}
+
+	lo, hi := h.occupiedMin, h.occupiedMax
+	return AABB{
+		Min: mgl64.Vec3{float64(lo.x), float64(lo.y), float64(lo.z)}.Mul(h.cellSize),
+		Max: mgl64.Vec3{float64(hi.x + 1), float64(hi.y + 1), float64(hi.z + 1)}.Mul(h.cellSize),
+	}, true
+}
+
+// empty reports whether the hash holds no proxies, which is common for the
+// static hash of a world without static bodies
+func (h *SpatialHash) empty() bool {
+	return len(h.cells) == 0 && len(h.large) == 0
+}
+
+// place stores a proxy in all the cells covered by the AABB
+func (h *SpatialHash) place(id int32, aabb AABB) {
+	p := &h.proxies[id]
+	p.aabb = aabb
+	p.min, p.max = h.cellRange(aabb)
+
+	cellCount := int64(p.max.x-p.min.x+1) * int64(p.max.y-p.min.y+1) * int64(p.max.z-p.min.z+1)
+	if cellCount > maxProxyCells {
+		p.large = true
+		h.large = append(h.large, id)
+		return
+	}
+
+	p.large = false
+	if h.occupiedValid {
+		h.occupiedMin = minCell(h.occupiedMin, p.min)
+		h.occupiedMax = maxCell(h.occupiedMax, p.max)
+	}
+	for x := p.min.x; x <= p.max.x; x++ {
+		for y := p.min.y; y <= p.max.y; y++ {
+			for z := p.min.z; z <= p.max.z; z++ {
+				key := cellKey{x, y, z}.pack()
+				i, ok := h.index[key]
+				if !ok {
+					i = int32(len(h.cells))
+					h.index[key] = i
+					h.cells = append(h.cells, cell{key: key})
+				}
+				h.cells[i].ids = append(h.cells[i].ids, id)
+			}
+		}
+	}
+}
+
+// unplace removes a proxy from all the cells it is currently stored in
+func (h *SpatialHash) unplace(id int32) {
+	p := &h.proxies[id]
+	if p.large {
+		h.large = removeID(h.large, id)
+		return
+	}
+
+	for x := p.min.x; x <= p.max.x; x++ {
+		for y := p.min.y; y <= p.max.y; y++ {
+			for z := p.min.z; z <= p.max.z; z++ {
+				key := cellKey{x, y, z}.pack()
+				i := h.index[key]
+				h.cells[i].ids = removeID(h.cells[i].ids, id)
+				if len(h.cells[i].ids) == 0 {
+					h.removeCell(i)
+				}
+			}
+		}
+	}
+}
+
+// removeCell deletes an empty cell by moving the last cell into its slot
+func (h *SpatialHash) removeCell(i int32) {
+	delete(h.index, h.cells[i].key)
+	h.occupiedValid = false
+
+	last := int32(len(h.cells) - 1)
+	if i != last {
+		h.cells[i] = h.cells[last]
+		h.index[h.cells[i].key] = i
+	}
+	h.cells[last] = cell{}
+	h.cells = h.cells[:last]
+}
+
+// cellRange returns the inclusive range of cells covered by an AABB
+func (h *SpatialHash) cellRange(aabb AABB) (cellKey, cellKey) {
+	return h.cellOf(aabb.Min), h.cellOf(aabb.Max)
+}
+
+// cellOf returns the cell containing a point
+func (h *SpatialHash) cellOf(point mgl64.Vec3) cellKey {
+	return cellKey{
+		x: h.coordinate(point[0]),
+		y: h.coordinate(point[1]),
+		z: h.coordinate(point[2]),
+	}
+}
+
+// coordinate converts a world coordinate to a cell coordinate, clamped so that
+// bodies flung far away cannot overflow the cell key
+func (h *SpatialHash) coordinate(value float64) int32 {
+	c := math.Floor(value / h.cellSize)
+	if c < -cellCoordinateLimit {
+		return -cellCoordinateLimit
+	}
+	if c > cellCoordinateLimit {
+		return cellCoordinateLimit
+	}
+	return int32(c)
+}
+
+// minCell returns the component-wise minimum of two cells
+func minCell(a, b cellKey) cellKey {
+	return cellKey{
+		x: min(a.x, b.x),
+		y: min(a.y, b.y),
+		z: min(a.z, b.z),
+	}
+}
+
+// maxCell returns the component-wise maximum of two cells
+func maxCell(a, b cellKey) cellKey {
+	return cellKey{
+		x: max(a.x, b.x),
+		y: max(a.y, b.y),
+		z: max(a.z, b.z),
+	}
+}
+
+// removeID removes an id from a slice without preserving order
+func removeID(ids []int32, id int32) []int32 {
+	for i, v := range ids {
+		if v == id {
+			ids[i] = ids[len(ids)-1]
+			return ids[:len(ids)-1]
+		}
+	}
+	return ids
+}
+
+// overlaps is AABB.Overlaps without copying the boxes, used in the hot pair loop
+func overlaps(a, b *AABB) bool {
+	return a.Min[0] < b.Max[0] && a.Max[0] > b.Min[0] &&
+		a.Min[1] < b.Max[1] && a.Max[1] > b.Min[1] &&
+		a.Min[2] < b.Max[2] && a.Max[2] > b.Min[2]
+}
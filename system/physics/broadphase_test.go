@@ -0,0 +1,150 @@
+package physics
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/anthdm/hollywood/actor"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+func TestSpatialHashPairsMatchBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	h := NewSpatialHash(defaultCellSize)
+
+	var boxes []AABB
+	for i := 0; i < 500; i++ {
+		center := mgl64.Vec3{rng.Float64() * 40, rng.Float64() * 10, rng.Float64() * 40}
+		halfExtents := mgl64.Vec3{0.25 + rng.Float64()*2, 0.25 + rng.Float64()*2, 0.25 + rng.Float64()*2}
+		boxes = append(boxes, NewAABB(center, halfExtents))
+		h.Insert(boxes[i])
+	}
+	// A body larger than the grid limit must still be paired with everything it touches
+	boxes = append(boxes, NewAABB(mgl64.Vec3{20, 0, 20}, mgl64.Vec3{100, 0.5, 100}))
+	h.Insert(boxes[len(boxes)-1])
+
+	// Move half of the bodies so that incremental updates are exercised
+	for i := 0; i < len(boxes); i += 2 {
+		offset := mgl64.Vec3{rng.Float64() * 3, rng.Float64() * 3, rng.Float64() * 3}
+		boxes[i] = AABB{Min: boxes[i].Min.Add(offset), Max: boxes[i].Max.Add(offset)}
+		h.Update(int32(i), boxes[i])
+	}
+
+	expected := make(map[[2]int32]bool)
+	for i := range boxes {
+		for j := i + 1; j < len(boxes); j++ {
+			if boxes[i].Overlaps(boxes[j]) {
+				expected[[2]int32{int32(i), int32(j)}] = true
+			}
+		}
+	}
+
+	found := make(map[[2]int32]bool)
+	h.Pairs(func(a, b int32) {
+		key := [2]int32{min(a, b), max(a, b)}
+		if found[key] {
+			t.Errorf("pair %v reported more than once", key)
+		}
+		found[key] = true
+	})
+
+	if len(found) != len(expected) {
+		t.Errorf("expected %d pairs, got %d", len(expected), len(found))
+	}
+	for key := range expected {
+		if !found[key] {
+			t.Errorf("missing pair %v", key)
+		}
+	}
+}
+
+// newBenchmarkWorld builds a world of cubes laid out like the playground grid,
+// with every fourth cube stacked on top of its neighbour to produce contacts.
+// Its workers are stopped once the benchmark is done.
+func newBenchmarkWorld(tb testing.TB, count int) *Physics {
+	p := newTestPhysics()
+	tb.Cleanup(func() { p.workers.stop() })
+	p.modelBounds["cube"] = mgl64.Vec3{1, 1, 1}
+
+	side := 1
+	for side*side < count {
+		side++
+	}
+
+	for i := 0; i < count; i++ {
+		x, z := i%side, i/side
+		y := 0.5
+		if i%4 == 3 {
+			x--
+			y = 1.5
+		}
+		p.Register(actor.NewPID("local", fmt.Sprintf("cube_%d", i)), EntityRigidBody{
+			Position:   mgl64.Vec3{float64(x * 2), y, float64(z * 2)},
+			Scale:      mgl64.Vec3{1, 1, 1},
+			ModelName:  "cube",
+			EntityType: "cube",
+		})
+	}
+
+	return p
+}
+
+// BenchmarkStep10k measures a full physics tick with 10k bodies. The ticks/s
+// metric must stay above the 256 Hz server tick rate.
+func BenchmarkStep10k(b *testing.B) {
+	p := newBenchmarkWorld(b, 10_000)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p.Step(1.0 / 256)
+	}
+	b.ReportMetric(float64(b.N)/b.Elapsed().Seconds(), "ticks/s")
+}
+
+func BenchmarkSpatialHashPairs10k(b *testing.B) {
+	p := newBenchmarkWorld(b, 10_000)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		pairs := 0
+		p.broadphase.Pairs(func(a, b int32) { pairs++ })
+	}
+}
+
+func BenchmarkSpatialHashUpdate10k(b *testing.B) {
+	p := newBenchmarkWorld(b, 10_000)
+	offsets := []mgl64.Vec3{{0.01, 0, 0}, {-0.01, 0, 0}}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		offset := offsets[i%2]
+		for _, body := range p.bodies {
+			body.Position = body.Position.Add(offset)
+			p.broadphase.Update(body.proxy, body.bounds())
+		}
+	}
+}
+
+func TestSpatialHashOccupiedBoundsFollowTheGrid(t *testing.T) {
+	h := NewSpatialHash(defaultCellSize)
+	if _, ok := h.occupied(); ok {
+		t.Fatal("an empty hash has no occupied cells")
+	}
+
+	near := h.Insert(NewAABB(mgl64.Vec3{0.5, 0.5, 0.5}, mgl64.Vec3{0.25, 0.25, 0.25}))
+	far := h.Insert(NewAABB(mgl64.Vec3{10.5, 0.5, 0.5}, mgl64.Vec3{0.25, 0.25, 0.25}))
+	h.Insert(NewAABB(mgl64.Vec3{}, mgl64.Vec3{100, 0.5, 100}))
+
+	expected := AABB{Max: mgl64.Vec3{11, 1, 1}}
+	if bounds, ok := h.occupied(); !ok || bounds != expected {
+		t.Fatalf("expected %v without the large proxy, got %v", expected, bounds)
+	}
+
+	h.Remove(far)
+	h.Update(near, NewAABB(mgl64.Vec3{-1.5, 0.5, 0.5}, mgl64.Vec3{0.25, 0.25, 0.25}))
+	expected = AABB{Min: mgl64.Vec3{-2, 0, 0}, Max: mgl64.Vec3{-1, 1, 1}}
+	if bounds, ok := h.occupied(); !ok || bounds != expected {
+		t.Fatalf("expected the bounds to shrink to %v, got %v", expected, bounds)
+	}
+}
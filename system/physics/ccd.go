@@ -0,0 +1,131 @@
+package physics
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+const (
+	// ccdIterations bounds the conservative advancement steps of a sweep
+	ccdIterations = 32
+
+	// ccdTolerance is how close a swept body has to get to what it hits to
+	// count as touching it
+	ccdTolerance = 1e-3
+
+	// ccdOverlap is how far a swept body is left inside what it hit, so that
+	// the contact is found and resolved in the same step
+	ccdOverlap = 0.01
+)
+
+// sweep moves a body with continuous collision back along the path it took
+// during the step to where it first hit something, so it cannot pass through
+// thin geometry or the ground between two ticks. The path is a straight line from start and
+// the body keeps its orientation at the end of the step. Bodies that moved less
+// than their smallest extent cannot skip anything and are left alone.
+func (p *Physics) sweep(b *body, start mgl64.Vec3) {
+	motion := b.Position.Sub(start)
+	extents := b.worldExtents()
+	if motion.Len() < math.Min(extents.X(), math.Min(extents.Y(), extents.Z())) {
+		return
+	}
+
+	shape := b.convex()
+	shape.position = start
+	bounds := NewAABB(start, extents)
+	swept := AABB{
+		Min: minVec(bounds.Min, bounds.Min.Add(motion)),
+		Max: maxVec(bounds.Max, bounds.Max.Add(motion)),
+	}
+
+	hit := 1.0
+	test := func(other *body) {
+		if other == b || other.Sensor || !other.collides() || !b.interacts(other) || p.jointed(b, other) {
+			return
+		}
+
+		if other.shape.mesh != nil {
+			other.shape.mesh.hash.Query(swept, func(id int32) {
+				triangle := other.shape.mesh.triangle(id)
+				if t, ok := timeOfImpact(shape, motion, &triangle); ok && t < hit {
+					hit = t
+				}
+			})
+			return
+		}
+
+		target := other.convex()
+		if t, ok := timeOfImpact(shape, motion, &target); ok && t < hit {
+			hit = t
+		}
+	}
+	p.broadphase.Query(swept, func(id int32) { test(p.owners[id]) })
+	p.staticBroadphase.Query(swept, func(id int32) { test(p.staticOwners[id]) })
+	if !b.Sensor {
+		hit = math.Min(hit, p.groundImpact(shape, motion, swept))
+	}
+
+	if hit < 1 {
+		b.Position = start.Add(motion.Mul(hit))
+	}
+}
+
+// groundImpact returns the fraction of its motion after which a moving shape
+// first touches the ground, or 1 when it does not. The floor plane is reached
+// when the lowest point of the shape gets to y=0, terrain is swept against the
+// triangles under the path. Shapes already below the floor are left to the
+// discrete contacts.
+func (p *Physics) groundImpact(shape convex, motion mgl64.Vec3, swept AABB) float64 {
+	if p.ground.shape.heightfield == nil {
+		lowest := shape.support(mgl64.Vec3{0, -1, 0}).Y()
+		if lowest < 0 || lowest+motion.Y() >= 0 {
+			return 1
+		}
+		return math.Min((lowest+ccdOverlap)/-motion.Y(), 1)
+	}
+
+	hit := 1.0
+	p.ground.terrainTriangles(swept, func(triangle *convex) {
+		if t, ok := timeOfImpact(shape, motion, triangle); ok && t < hit {
+			hit = t
+		}
+	})
+	return hit
+}
+
+// timeOfImpact returns the fraction of its motion after which a moving shape
+// first touches another one, by conservative advancement: the shape can always
+// travel as far as the distance GJK measures without reaching the other one.
+// Shapes already overlapping at the start are left to the discrete contacts.
+func timeOfImpact(a convex, motion mgl64.Vec3, b *convex) (float64, bool) {
+	from := a.position
+	t := 0.0
+	for i := 0; i < ccdIterations; i++ {
+		a.position = from.Add(motion.Mul(t))
+		result := gjk(&a, b)
+		if result.overlap {
+			return t, t > 0
+		}
+
+		distance := result.distance - a.radius - b.radius
+		if distance < 0 && t == 0 {
+			return 0, false
+		}
+
+		// Moving away from the closest point, the shapes only get further apart
+		closing := motion.Dot(result.normal)
+		if closing <= 0 {
+			return 0, false
+		}
+		if distance <= ccdTolerance {
+			return math.Min(t+(distance+ccdOverlap)/closing, 1), true
+		}
+
+		t += distance / closing
+		if t > 1 {
+			return 0, false
+		}
+	}
+	return t, true
+}
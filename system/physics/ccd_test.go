@@ -0,0 +1,94 @@
+package physics
+
+import (
+	"testing"
+
+	"github.com/anthdm/hollywood/actor"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+func TestFastProjectileNeverPassesThroughThinWall(t *testing.T) {
+	none := 0.0
+	walls := map[string]EntityRigidBody{
+		"box": {Position: mgl64.Vec3{5, 5, 0}, Scale: mgl64.Vec3{0.05, 4, 4}, BodyType: BodyStatic},
+		"mesh": {Position: mgl64.Vec3{5, 5, 0}, Scale: mgl64.Vec3{1, 1, 1}, Shape: TriangleMeshShape([][3]mgl64.Vec3{
+			{{0, -2, -2}, {0, 2, -2}, {0, 2, 2}},
+			{{0, -2, -2}, {0, 2, 2}, {0, -2, 2}},
+		})},
+	}
+
+	for name, wall := range walls {
+		for _, continuous := range []bool{false, true} {
+			p := newTestPhysics()
+			p.Register(actor.NewPID("local", "wall"), wall)
+
+			// Moving 1.5 units per step, the projectile jumps over the wall between two steps
+			projectile := actor.NewPID("local", "projectile")
+			p.Register(projectile, EntityRigidBody{
+				Position:            mgl64.Vec3{0, 5, 0},
+				Velocity:            mgl64.Vec3{1.5 / FixedDeltaTime, 0, 0},
+				Scale:               mgl64.Vec3{1, 1, 1},
+				Shape:               SphereShape(0.1),
+				GravityScale:        &none,
+				Material:            &IceMaterial,
+				ContinuousCollision: continuous,
+			})
+
+			for i := 0; i < 16; i++ {
+				p.Step(FixedDeltaTime)
+			}
+
+			x := p.entities[projectile].Position.X()
+			switch {
+			case continuous && x > 5:
+				t.Errorf("%s wall: projectile with continuous collision passed through, got x=%f", name, x)
+			case !continuous && x < 5:
+				t.Errorf("%s wall: projectile without continuous collision was expected to tunnel, got x=%f", name, x)
+			}
+		}
+	}
+}
+
+func TestFastBodyStopsWhereItReachesTheGround(t *testing.T) {
+	grounds := map[string]*EntityRigidBody{
+		"floor": nil,
+		"terrain": {
+			Position: mgl64.Vec3{0, 2, 0},
+			BodyType: BodyStatic,
+			Shape:    HeightfieldShape(NewHeightfield(9, 9, 1, func(x, z float64) float64 { return 0 })),
+		},
+	}
+
+	for name, ground := range grounds {
+		for _, continuous := range []bool{false, true} {
+			p := newTestPhysics()
+			surface := 0.0
+			if ground != nil {
+				p.Register(actor.NewPID("local", "terrain"), *ground)
+				surface = ground.Position.Y()
+			}
+
+			// Dropped at 3 units per step along both axes, the ball reaches the
+			// ground at x=1.35 but ends the step 1.4 units below it at x=3
+			ball := actor.NewPID("local", "ball")
+			p.Register(ball, EntityRigidBody{
+				Position:            mgl64.Vec3{0, surface + 1.6, 0},
+				Velocity:            mgl64.Vec3{3 / FixedDeltaTime, -3 / FixedDeltaTime, 0},
+				Scale:               mgl64.Vec3{1, 1, 1},
+				Shape:               SphereShape(0.25),
+				ContinuousCollision: continuous,
+			})
+			p.Step(FixedDeltaTime)
+
+			position := p.entities[ball].Position
+			switch {
+			case position.Y() < surface:
+				t.Errorf("%s: ball ended below the ground, got %v", name, position)
+			case continuous && position.X() > 1.5:
+				t.Errorf("%s: ball with continuous collision should stop where it reached the ground, got %v", name, position)
+			case !continuous && position.X() < 1.5:
+				t.Errorf("%s: ball without continuous collision was expected to land at the end of the step, got %v", name, position)
+			}
+		}
+	}
+}
@@ -0,0 +1,439 @@
+package physics
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+const (
+	// characterIterations bounds how many times a character is pushed out of
+	// the geometry it touches after every piece of its movement
+	characterIterations = 4
+
+	// groundProbe is how far below its feet a character looks for ground
+	groundProbe = 0.05
+
+	// stepTolerance is how much higher and further a step up must take a
+	// character to count as climbing a ledge
+	stepTolerance = 1e-3
+)
+
+// CharacterController configures a body moved by the character controller
+// instead of by contacts. The body becomes kinematic and collides as an upright
+// capsule, both when it moves and when other bodies run into it. A body without
+// a capsule shape gets one fitted inside its collision box: its radius is the
+// smallest horizontal half extent and it spans the full height of the box.
+type CharacterController struct {
+	MaxSlopeAngle float64 // Steepest walkable slope, in radians
+	StepHeight    float64 // Tallest ledge climbed without jumping
+	JumpSpeed     float64 // Upwards speed given by a jump
+	CoyoteTime    float64 // Seconds after leaving the ground during which a jump is still allowed
+	JumpBuffer    float64 // Seconds a jump asked for in the air is remembered before landing
+}
+
+// DefaultCharacterController suits a character about two units tall
+var DefaultCharacterController = CharacterController{
+	MaxSlopeAngle: math.Pi / 4,
+	StepHeight:    0.3,
+	JumpSpeed:     7.0,
+	CoyoteTime:    0.1,
+	JumpBuffer:    0.15,
+}
+
+// character is the state of the controller of a body
+type character struct {
+	CharacterController
+
+	walk          mgl64.Vec3 // Horizontal velocity asked for by the owner
+	sinceGrounded float64    // Seconds since the character last stood on walkable ground
+	sinceJump     float64    // Seconds since the owner last asked for a jump
+	onGround      bool
+	groundNormal  mgl64.Vec3
+	groundBody    *body // The floor plane or the body the character stands on
+}
+
+// slideHits tells what a character ran into while sliding
+type slideHits struct {
+	wall    bool // A wall or a slope too steep to walk on
+	ceiling bool
+}
+
+// setCharacter attaches a character controller to a body, which makes it an
+// upright kinematic body that is never rotated
+func (b *body) setCharacter(settings *CharacterController) {
+	b.character = nil
+	if settings == nil {
+		return
+	}
+
+	b.BodyType = BodyKinematic
+	b.FixedRotation = true
+	b.Orientation = mgl64.QuatIdent()
+	b.character = &character{
+		CharacterController: *settings,
+		sinceGrounded:       math.Inf(1),
+		sinceJump:           math.Inf(1),
+	}
+}
+
+// capsule returns the bottom and top centers of the capsule of a character
+// together with its radius
+func (b *body) capsule() (mgl64.Vec3, mgl64.Vec3, float64) {
+	offset := mgl64.Vec3{0, b.shape.halfHeight, 0}
+	return b.Position.Sub(offset), b.Position.Add(offset), b.shape.radius
+}
+
+// walkable reports whether a surface with the given normal can be stood on
+func (c *character) walkable(normal mgl64.Vec3) bool {
+	return normal.Y() >= math.Cos(c.MaxSlopeAngle)
+}
+
+// moveCharacter walks a character for one step. Jumps are allowed shortly after
+// leaving the ground and are remembered for a moment when asked for too early.
+// Blocked walks are retried on top of low ledges, and a character walking down
+// a slope or a stair keeps its feet on the ground.
+func (p *Physics) moveCharacter(b *body, deltaTime float64) {
+	c := b.character
+
+	vertical := b.Velocity.Y()
+	jumped := false
+	if c.sinceJump <= c.JumpBuffer && (c.onGround || c.sinceGrounded <= c.CoyoteTime) {
+		vertical = c.JumpSpeed
+		jumped = true
+		c.sinceJump = math.Inf(1)
+		c.sinceGrounded = math.Inf(1)
+	} else if c.onGround {
+		vertical = 0
+	}
+	c.sinceJump += deltaTime
+	c.sinceGrounded += deltaTime
+
+	// On the ground the walk follows the slope, so walking downhill does not
+	// launch the character into the air
+	walk := c.walk
+	if c.onGround && !jumped && walk != (mgl64.Vec3{}) {
+		along := walk.Sub(c.groundNormal.Mul(walk.Dot(c.groundNormal)))
+		if along.Len() > 0 {
+			walk = along.Normalize().Mul(walk.Len())
+		}
+	}
+
+	b.Velocity = mgl64.Vec3{c.walk.X(), vertical, c.walk.Z()}
+	if !c.onGround || jumped {
+		p.ApplyGravity(&b.EntityRigidBody, deltaTime)
+	}
+
+	start := b.Position
+	hits := p.slide(b, walk.Mul(deltaTime).Add(mgl64.Vec3{0, b.Velocity.Y() * deltaTime, 0}))
+	if hits.wall && c.onGround && !jumped && c.StepHeight > 0 {
+		p.stepUp(b, start, c.walk.Mul(deltaTime))
+	}
+	if hits.ceiling && b.Velocity.Y() > 0 {
+		b.Velocity = mgl64.Vec3{b.Velocity.X(), 0, b.Velocity.Z()}
+	}
+
+	// Only a character that is not moving up can land
+	var normal mgl64.Vec3
+	var ground *body
+	var ok bool
+	if c.onGround && !jumped {
+		normal, ground, ok = p.snapToGround(b)
+	} else {
+		normal, ground, ok = p.findGround(b)
+	}
+	c.onGround = ok && b.Velocity.Y() <= 0
+	c.groundNormal, c.groundBody = mgl64.Vec3{}, nil
+	if c.onGround {
+		c.groundNormal, c.groundBody = normal, ground
+		c.sinceGrounded = 0
+		b.Velocity = mgl64.Vec3{b.Velocity.X(), 0, b.Velocity.Z()}
+	}
+	b.grounded = c.onGround
+}
+
+// slide moves a character by displacement, stopping at and sliding along the
+// non-sensor bodies and the floor plane it runs into. The movement is split in
+// pieces shorter than the capsule radius so that it cannot tunnel through thin
+// geometry.
+func (p *Physics) slide(b *body, displacement mgl64.Vec3) slideHits {
+	var hits slideHits
+	_, _, radius := b.capsule()
+
+	from := b.Position
+	candidates := p.characterCandidates(b, from, from.Add(displacement))
+
+	pieces := math.Max(math.Ceil(displacement.Len()/(radius*0.5)), 1)
+	step := displacement.Mul(1 / pieces)
+	for piece := 0.0; piece < pieces; piece++ {
+		b.Position = b.Position.Add(step)
+
+		for i := 0; i < characterIterations; i++ {
+			resolved := true
+			for _, other := range candidates {
+				normal, depth, ok := p.capsuleContact(b, other)
+				if !ok {
+					continue
+				}
+				resolved = false
+				b.Position = b.Position.Add(normal.Mul(depth))
+
+				walkable := b.character.walkable(normal)
+				if !walkable && normal.Y() > -0.5 {
+					hits.wall = true
+				}
+				if normal.Y() <= -0.5 {
+					hits.ceiling = true
+				}
+
+				// Stop moving into the surface. Steep slopes are treated as upright
+				// walls so that walking into them never climbs them.
+				if !walkable && normal.Y() > 0 {
+					if flat := (mgl64.Vec3{normal.X(), 0, normal.Z()}); flat.Len() > 0 {
+						normal = flat.Normalize()
+					}
+				}
+				if into := step.Dot(normal); into < 0 {
+					step = step.Sub(normal.Mul(into))
+				}
+			}
+			if resolved {
+				break
+			}
+		}
+	}
+
+	return hits
+}
+
+// stepUp retries a blocked walk from start lifted by the step height. The result
+// is kept when the character lands on walkable ground higher and further along
+// the walk than it got without stepping.
+func (p *Physics) stepUp(b *body, start, walk mgl64.Vec3) {
+	blocked := b.Position
+	horizontal := func(position mgl64.Vec3) float64 {
+		offset := position.Sub(start)
+		return mgl64.Vec3{offset.X(), 0, offset.Z()}.Len()
+	}
+
+	b.Position = start
+	p.slide(b, mgl64.Vec3{0, b.character.StepHeight, 0})
+	lifted := b.Position.Y() - start.Y()
+	p.slide(b, mgl64.Vec3{walk.X(), 0, walk.Z()})
+	b.Position = b.Position.Sub(mgl64.Vec3{0, p.dropDistance(b, lifted+groundProbe), 0})
+
+	if _, _, ok := p.findGround(b); ok && b.Position.Y() > blocked.Y()+stepTolerance &&
+		horizontal(b.Position) > horizontal(blocked)+stepTolerance {
+		return
+	}
+	b.Position = blocked
+}
+
+// snapToGround keeps a character that was standing on the ground on it, moving
+// it straight down when it walked off a low ledge or down a slope. Nothing moves
+// when the ground is further below than the step height.
+func (p *Physics) snapToGround(b *body) (mgl64.Vec3, *body, bool) {
+	from := b.Position
+	b.Position = b.Position.Sub(mgl64.Vec3{0, p.dropDistance(b, b.character.StepHeight), 0})
+	if normal, ground, ok := p.findGround(b); ok {
+		return normal, ground, true
+	}
+	b.Position = from
+	return mgl64.Vec3{}, nil, false
+}
+
+// dropDistance returns how far a character can move straight down, up to
+// distance, before landing on something. Walls the character is already
+// touching do not stop it.
+func (p *Physics) dropDistance(b *body, distance float64) float64 {
+	from := b.Position
+	candidates := p.characterCandidates(b, from, from.Sub(mgl64.Vec3{0, distance, 0}))
+	defer func() { b.Position = from }()
+
+	touches := func(drop float64) bool {
+		b.Position = from.Sub(mgl64.Vec3{0, drop, 0})
+		for _, other := range candidates {
+			if normal, _, ok := p.capsuleContact(b, other); ok && normal.Y() > 0 {
+				return true
+			}
+		}
+		return false
+	}
+	if !touches(distance) {
+		return distance
+	}
+
+	// Bisect the drop, the last free distance is within distance/2^16 of the contact
+	low, high := 0.0, distance
+	for i := 0; i < 16; i++ {
+		if middle := (low + high) / 2; touches(middle) {
+			high = middle
+		} else {
+			low = middle
+		}
+	}
+	return low
+}
+
+// findGround returns the walkable surface right below a character, preferring
+// the flattest one when it stands on several
+func (p *Physics) findGround(b *body) (mgl64.Vec3, *body, bool) {
+	from := b.Position
+	probe := from.Sub(mgl64.Vec3{0, groundProbe, 0})
+	candidates := p.characterCandidates(b, from, probe)
+
+	b.Position = probe
+	defer func() { b.Position = from }()
+
+	var normal mgl64.Vec3
+	var ground *body
+	for _, other := range candidates {
+		n, ok := p.supportNormal(b, other)
+		if !ok || !b.character.walkable(n) {
+			continue
+		}
+		if ground == nil || n.Y() > normal.Y() {
+			normal, ground = n, other
+		}
+	}
+	return normal, ground, ground != nil
+}
+
+// supportNormal returns the normal of the surface of another body a character
+// rests on. A character standing on the rim of a box touches its edge, in which
+// case the flattest face meeting at that edge is the surface.
+func (p *Physics) supportNormal(b, other *body) (mgl64.Vec3, bool) {
+	normal, _, ok := p.capsuleContact(b, other)
+	if !ok || normal.Y() <= 0 || other.ground || other.shape.kind != ShapeBox {
+		return normal, ok && normal.Y() > 0
+	}
+
+	bottom, _, _ := b.capsule()
+	box := other.box()
+	delta := box.closestPoint(bottom).Sub(box.center)
+	for axis := 0; axis < 3; axis++ {
+		distance := delta.Dot(box.axes[axis])
+		if math.Abs(distance) < box.half[axis]-1e-6 {
+			continue
+		}
+		face := box.axes[axis]
+		if distance < 0 {
+			face = face.Mul(-1)
+		}
+		if face.Y() > normal.Y() {
+			normal = face
+		}
+	}
+	return normal, true
+}
+
+// characterCandidates returns the bodies a character may touch while moving
+// between two positions. The floor plane is included when it is in reach.
+func (p *Physics) characterCandidates(b *body, from, to mgl64.Vec3) []*body {
+	_, _, radius := b.capsule()
+	reach := b.halfExtents.Add(mgl64.Vec3{radius, radius, radius})
+	bounds := AABB{
+		Min: minVec(from, to).Sub(reach),
+		Max: maxVec(from, to).Add(reach),
+	}
+
+	var candidates []*body
+	if bounds.Min.Y() < p.ground.groundTop(bounds) {
+		candidates = append(candidates, p.ground)
+	}
+	collect := func(other *body) {
+		if other != b && !other.Sensor && b.interacts(other) {
+			candidates = append(candidates, other)
+		}
+	}
+	p.broadphase.Query(bounds, func(id int32) { collect(p.owners[id]) })
+	p.staticBroadphase.Query(bounds, func(id int32) { collect(p.staticOwners[id]) })
+	return candidates
+}
+
+// capsuleContact returns the direction and depth a character must be moved to
+// stop overlapping another body
+func (p *Physics) capsuleContact(b, other *body) (mgl64.Vec3, float64, bool) {
+	bottom, top, radius := b.capsule()
+	if other.ground {
+		height, normal := other.groundAt(bottom)
+		if depth := radius - (bottom.Y()-height)*normal.Y(); depth > 0 {
+			return normal, depth, true
+		}
+		return mgl64.Vec3{}, 0, false
+	}
+	if other.shape.kind == ShapeBox {
+		return capsuleBoxContact(bottom, top, radius, other.box())
+	}
+
+	// Other shapes push the capsule out through GJK and EPA, meshes through
+	// their deepest triangle
+	capsule := convex{
+		position: bottom.Add(top).Mul(0.5),
+		rotation: mgl64.Ident3(),
+		half:     mgl64.Vec3{0, top.Sub(bottom).Len() / 2, 0},
+		radius:   radius,
+	}
+	if other.shape.mesh == nil {
+		shape := other.convex()
+		normal, depth, ok := convexSeparation(&capsule, &shape)
+		return normal.Mul(-1), depth, ok
+	}
+
+	var normal mgl64.Vec3
+	depth, found := 0.0, false
+	bounds := NewAABB(capsule.position, capsule.half.Add(mgl64.Vec3{radius, radius, radius}))
+	other.shape.mesh.hash.Query(bounds, func(id int32) {
+		triangle := other.shape.mesh.triangle(id)
+		if n, d, ok := convexSeparation(&capsule, &triangle); ok && d > depth {
+			normal, depth, found = n.Mul(-1), d, true
+		}
+	})
+	return normal, depth, found
+}
+
+// capsuleBoxContact finds the overlap between a capsule and a box. The closest
+// points of the capsule segment and the box are found by projecting back and
+// forth between them, which converges because both shapes are convex.
+func capsuleBoxContact(bottom, top mgl64.Vec3, radius float64, box orientedBox) (mgl64.Vec3, float64, bool) {
+	segment := top.Sub(bottom)
+	length := segment.Dot(segment)
+	closest := func(point mgl64.Vec3) mgl64.Vec3 {
+		if length == 0 {
+			return bottom
+		}
+		t := math.Max(0, math.Min(1, point.Sub(bottom).Dot(segment)/length))
+		return bottom.Add(segment.Mul(t))
+	}
+
+	point := closest(box.center)
+	for i := 0; i < 4; i++ {
+		point = closest(box.closestPoint(point))
+	}
+
+	delta := point.Sub(box.closestPoint(point))
+	if distance := delta.Len(); distance > 1e-9 {
+		if distance >= radius {
+			return mgl64.Vec3{}, 0, false
+		}
+		return delta.Mul(1 / distance), radius - distance, true
+	}
+
+	// The segment runs through the box, so push the capsule out through the
+	// face it overlaps the least
+	center := bottom.Add(top).Mul(0.5)
+	var normal mgl64.Vec3
+	depth := math.Inf(1)
+	for axis := 0; axis < 3; axis++ {
+		distance := center.Sub(box.center).Dot(box.axes[axis])
+		extent := radius + math.Abs(segment.Dot(box.axes[axis]))/2
+		if overlap := box.half[axis] + extent - math.Abs(distance); overlap < depth {
+			depth = overlap
+			normal = box.axes[axis]
+			if distance < 0 {
+				normal = normal.Mul(-1)
+			}
+		}
+	}
+	return normal, depth, true
+}
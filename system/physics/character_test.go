@@ -0,0 +1,162 @@
+package physics
+
+import (
+	"math"
+	"testing"
+
+	"github.com/anthdm/hollywood/actor"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// registerCharacter adds a character with a capsule of radius 0.4 and height 2
+// standing on the floor at x
+func registerCharacter(p *Physics, x float64) *body {
+	pid := actor.NewPID("local", "character")
+	p.Register(pid, EntityRigidBody{
+		Position:  mgl64.Vec3{x, 1, 0},
+		Scale:     mgl64.Vec3{0.8, 2, 0.8},
+		Character: &DefaultCharacterController,
+	})
+	return p.entities[pid]
+}
+
+func TestCharacterSlidesAlongWallsAndStepsOntoLedges(t *testing.T) {
+	p := newTestPhysics()
+
+	wall := actor.NewPID("local", "wall")
+	ledge := actor.NewPID("local", "ledge")
+	p.Register(wall, EntityRigidBody{Position: mgl64.Vec3{3, 2, 0}, Scale: mgl64.Vec3{1, 4, 20}, BodyType: BodyStatic})
+	p.Register(ledge, EntityRigidBody{Position: mgl64.Vec3{0, 0.125, 14}, Scale: mgl64.Vec3{20, 0.25, 20}, BodyType: BodyStatic})
+	b := registerCharacter(p, 0)
+
+	// Walking diagonally into the wall slides along it onto the ledge
+	b.character.walk = mgl64.Vec3{3, 0, 3}
+	for i := 0; i < 256*3; i++ {
+		p.Step(FixedDeltaTime)
+	}
+
+	if x := b.Position.X(); math.Abs(x-2.1) > 0.01 {
+		t.Errorf("character should be stopped by the wall at x=2.1, got x=%f", x)
+	}
+	if z := b.Position.Z(); z < 5 {
+		t.Errorf("character should keep sliding along the wall, got z=%f", z)
+	}
+	if y := b.Position.Y(); math.Abs(y-1.25) > 0.01 {
+		t.Errorf("character should have stepped onto the ledge, got y=%f", y)
+	}
+	if !b.character.onGround || b.character.groundBody != p.entities[ledge] {
+		t.Error("character should report the ledge as its ground")
+	}
+	if b.character.groundNormal.Sub(mgl64.Vec3{0, 1, 0}).Len() > 1e-9 {
+		t.Errorf("ground normal should point up, got %v", b.character.groundNormal)
+	}
+}
+
+func TestCharacterClimbsOnlyWalkableSlopes(t *testing.T) {
+	for _, tc := range []struct {
+		angle float64
+		climb bool
+	}{
+		{angle: math.Pi / 6, climb: true},
+		{angle: math.Pi / 3, climb: false},
+	} {
+		p := newTestPhysics()
+
+		// A long ramp rising towards +X, leaving the floor around x=4
+		ramp := actor.NewPID("local", "ramp")
+		tilt := mgl64.QuatRotate(tc.angle, mgl64.Vec3{0, 0, 1})
+		p.Register(ramp, EntityRigidBody{Position: mgl64.Vec3{10, 0, 0}, Scale: mgl64.Vec3{12, 1, 4}, Orientation: tilt, BodyType: BodyStatic})
+		b := registerCharacter(p, 0)
+
+		b.character.walk = mgl64.Vec3{3, 0, 0}
+		for i := 0; i < 256*4; i++ {
+			p.Step(FixedDeltaTime)
+		}
+
+		climbed := b.Position.Y() > 2
+		if climbed != tc.climb {
+			t.Errorf("slope of %.0f degrees: expected climb=%v, character ended at %v", mgl64.RadToDeg(tc.angle), tc.climb, b.Position)
+		}
+	}
+}
+
+func TestCharacterJumpsWithCoyoteTimeAndJumpBuffer(t *testing.T) {
+	p := newTestPhysics()
+
+	platform := actor.NewPID("local", "platform")
+	p.Register(platform, EntityRigidBody{Position: mgl64.Vec3{0, 2.5, 0}, Scale: mgl64.Vec3{4, 1, 4}, BodyType: BodyStatic})
+	b := registerCharacter(p, 0)
+	b.Position = mgl64.Vec3{1.5, 4, 0}
+
+	step := func(count int) {
+		for i := 0; i < count; i++ {
+			p.Step(FixedDeltaTime)
+		}
+	}
+	step(16)
+	if !b.character.onGround {
+		t.Fatal("character should stand on the platform")
+	}
+
+	// Walk off the edge, a jump asked for right after leaving it still works
+	b.character.walk = mgl64.Vec3{4, 0, 0}
+	for b.character.onGround {
+		step(1)
+	}
+	step(int(DefaultCharacterController.CoyoteTime * 256 / 2))
+	b.character.walk = mgl64.Vec3{}
+	b.character.sinceJump = 0
+	step(1)
+	if b.Velocity.Y() <= 0 {
+		t.Fatalf("jump within coyote time should be allowed, vertical velocity %f", b.Velocity.Y())
+	}
+
+	// Falling towards the floor, a jump asked for just before landing happens on landing
+	for b.Position.Y() > 1.1 || b.Velocity.Y() > 0 {
+		step(1)
+	}
+	b.character.sinceJump = 0
+	jumped := false
+	for i := 0; i < int(DefaultCharacterController.JumpBuffer*256); i++ {
+		step(1)
+		if b.Velocity.Y() > 0 {
+			jumped = true
+			break
+		}
+	}
+	if !jumped {
+		t.Error("jump asked for before landing should be buffered")
+	}
+
+	// Long after leaving the ground a jump is ignored
+	step(64)
+	rising := b.Velocity.Y()
+	b.character.sinceJump = 0
+	step(1)
+	if b.Velocity.Y() >= rising {
+		t.Error("jump in the air after the coyote time should be ignored")
+	}
+}
+
+func TestOtherBodiesHitTheCapsuleOfCharacters(t *testing.T) {
+	p := newTestPhysics()
+	b := registerCharacter(p, 0)
+
+	// A crate over the corner of the collision box misses the rounded top of
+	// the capsule, one at the waist is pushed straight out sideways
+	corner := actor.NewPID("local", "corner")
+	waist := actor.NewPID("local", "waist")
+	p.Register(corner, EntityRigidBody{Position: mgl64.Vec3{0.42, 1.98, 0.42}, Scale: mgl64.Vec3{0.2, 0.2, 0.2}})
+	p.Register(waist, EntityRigidBody{Position: mgl64.Vec3{0.45, 1, 0}, Scale: mgl64.Vec3{0.2, 0.2, 0.2}})
+
+	if b.shape.kind != ShapeCapsule || b.shape.radius != 0.4 || b.shape.halfHeight != 0.6 {
+		t.Fatalf("character should collide as a capsule fitted in its box, got %+v", b.shape)
+	}
+	if overlapping(p.entities[corner], b) {
+		t.Error("a crate over the corner of the box should not touch the capsule")
+	}
+	normal, depth, ok := separation(p.entities[waist], b)
+	if !ok || math.Abs(depth-0.05) > 1e-6 || normal.Sub(mgl64.Vec3{-1, 0, 0}).Len() > 1e-6 {
+		t.Errorf("a crate at the waist should overlap the capsule by 0.05 along x, got %v %f %v", normal, depth, ok)
+	}
+}
@@ -0,0 +1,717 @@
+package physics
+
+import (
+	"math"
+	"slices"
+
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+const (
+	// solverIterations is how many times contacts are re-resolved per tick so that
+	// corrections made lower in a stack propagate to the bodies resting on top
+	solverIterations = 4
+
+	// restitutionThreshold is the approach speed below which contacts do not bounce
+	restitutionThreshold = 0.5
+
+	// penetrationSlop is the overlap tolerated before positions are corrected,
+	// which keeps resting contacts from jittering
+	penetrationSlop = 0.001
+)
+
+// shortcuts enables the cheaper paths taken for boxes resting flat, see
+// settleOnFloor and restingFace. Tests turn it off to compare them with the
+// full manifolds.
+var shortcuts = true
+
+// AABB is an axis-aligned bounding box in world space
+type AABB struct {
+	Min mgl64.Vec3
+	Max mgl64.Vec3
+}
+
+// NewAABB creates an AABB from its center and half extents
+func NewAABB(center, halfExtents mgl64.Vec3) AABB {
+	var box AABB
+	for i := 0; i < 3; i++ {
+		box.Min[i], box.Max[i] = center[i]-halfExtents[i], center[i]+halfExtents[i]
+	}
+	return box
+}
+
+// Overlaps returns true if both boxes intersect with a non-zero volume
+func (a AABB) Overlaps(b AABB) bool {
+	return a.Min.X() < b.Max.X() && a.Max.X() > b.Min.X() &&
+		a.Min.Y() < b.Max.Y() && a.Max.Y() > b.Min.Y() &&
+		a.Min.Z() < b.Max.Z() && a.Max.Z() > b.Min.Z()
+}
+
+// Center returns the center point of the box
+func (a AABB) Center() mgl64.Vec3 {
+	return a.Min.Add(a.Max).Mul(0.5)
+}
+
+// contact describes an overlap between two bodies found during a physics step
+type contact struct {
+	a, b       *body
+	normal     mgl64.Vec3    // Points from a towards b
+	tangents   [2]mgl64.Vec3 // Friction directions, perpendicular to the normal
+	depth      float64
+	distance   float64 // Distance between the centers along the normal when the contact was found
+	points     [maxManifoldPoints]mgl64.Vec3
+	pointCount int
+	solver     []manifoldPoint // Solver state of the points, in the buffer of the step
+	surface    combinedMaterial
+	impulse    float64 // Normal impulse applied by the solver during the step
+	feature    int32   // Triangle of the mesh of b or corner of box a on terrain that was touched, -1 for whole bodies
+	linear     bool    // Solved at a single point without turning either body, see restingFace
+}
+
+// manifoldPoint is the solver state of a point where two bodies touch, with
+// the impulses accumulated at that point during the current step. It is kept
+// apart from the contact so that finding contacts only moves their positions
+// around.
+type manifoldPoint struct {
+	bounce   float64 // Separating velocity the solver aims for
+	normal   contactAxis
+	tangents [2]contactAxis
+}
+
+// contactAxis is a direction along which the solver applies impulses at a
+// manifold point. Everything that only depends on where the point is on both
+// bodies is computed once per step, so the solver iterations stay cheap.
+type contactAxis struct {
+	armA, armB           mgl64.Vec3 // Turn the angular velocity of a body into speed along the axis
+	responseA, responseB mgl64.Vec3 // Change of angular velocity per unit of impulse
+	mass                 float64    // Effective mass felt by an impulse along the axis
+	impulse              float64    // Impulse accumulated during the step
+}
+
+// halfExtents returns the collision half extents of an entity, derived from the
+// bounds of its model scaled by the entity scale. Entities without known model
+// bounds are treated as unit cubes.
+func (p *Physics) halfExtents(entity EntityRigidBody) mgl64.Vec3 {
+	bounds, ok := p.modelBounds[entity.ModelName]
+	if !ok {
+		bounds = mgl64.Vec3{1, 1, 1}
+	}
+
+	return mgl64.Vec3{
+		bounds.X() * entity.Scale.X() / 2,
+		bounds.Y() * entity.Scale.Y() / 2,
+		bounds.Z() * entity.Scale.Z() / 2,
+	}
+}
+
+// bounds returns the world space AABB of a body
+func (b *body) bounds() AABB {
+	return NewAABB(b.Position, b.worldExtents())
+}
+
+// collides reports whether a body takes part in body-vs-body collision.
+// Flat boxes such as the floor plane have no volume to separate from, while
+// triangle meshes are surfaces that other bodies collide with. Terrain is
+// collided with as the ground of the world instead.
+func (b *body) collides() bool {
+	switch b.shape.kind {
+	case ShapeBox:
+		return b.halfExtents.X() > 0 && b.halfExtents.Y() > 0 && b.halfExtents.Z() > 0
+	case ShapeConvexHull:
+		return len(b.shape.points) > 0
+	case ShapeTriangleMesh:
+		return len(b.shape.mesh.triangles) > 0
+	case ShapeHeightfield:
+		return false
+	}
+	return b.shape.radius > 0
+}
+
+// narrowphase collects what a batch of candidate pairs found
+type narrowphase struct {
+	contacts []contact
+	triggers [][2]*body // Sensors and the bodies overlapping them
+	touched  []*body    // Sleeping bodies touched by a moving body
+}
+
+// findContacts tests the candidate pairs reported by the broadphases. Moving
+// bodies are paired with each other and then queried against the static hash
+// and the floor plane. The tests are split in batches over the workers and the
+// batches are merged in order, so contacts are always found in the same order.
+func (p *Physics) findContacts() []contact {
+	pairs := p.stepPairs[:0]
+	p.broadphase.Pairs(func(a, b int32) {
+		pairs = append(pairs, [2]*body{p.owners[a], p.owners[b]})
+	})
+	p.stepPairs = pairs
+
+	count := len(pairs) + len(p.active)
+	batch := p.workers.batch(count)
+	batches := (count + batch - 1) / batch
+	if len(p.narrowphase) < batches {
+		p.narrowphase = append(p.narrowphase, make([]narrowphase, batches-len(p.narrowphase))...)
+	}
+	results := p.narrowphase[:batches]
+
+	p.workers.run(count, batch, func(index, start, end int) {
+		out := &results[index]
+		for i := start; i < end; i++ {
+			if i < len(pairs) {
+				p.testPair(pairs[i][0], pairs[i][1], out)
+			} else {
+				p.testStatic(p.active[i-len(pairs)], out)
+			}
+		}
+	})
+
+	// A single batch already holds the contacts in order, its buffer is swapped
+	// with the one of the step instead of being copied
+	contacts := p.stepContacts[:0]
+	if batches == 1 {
+		contacts, results[0].contacts = results[0].contacts, contacts
+	}
+	for i := range results {
+		out := &results[i]
+		if batches > 1 {
+			contacts = append(contacts, out.contacts...)
+		}
+		for _, trigger := range out.triggers {
+			p.recordTrigger(trigger[0], trigger[1])
+		}
+		p.touchedSleeping = append(p.touchedSleeping, out.touched...)
+		out.contacts, out.triggers, out.touched = out.contacts[:0], out.triggers[:0], out.touched[:0]
+	}
+
+	p.stepContacts = contacts
+	return contacts
+}
+
+// settleOnFloor resolves the floor plane under a box lying flat on it right
+// after the box moved, when it touched nothing else during the last step. Its
+// contact with the floor is then the only one of the box, which the solver
+// settles in its first passes, so it is solved here the same way without ever
+// reaching the narrowphase and the solver. Most bodies of a large world rest
+// like this. It reports whether the floor was handled.
+func (p *Physics) settleOnFloor(b *body) bool {
+	if !shortcuts || b.touching || b.Sensor || b.shape.kind != ShapeBox || !b.axisAligned ||
+		b.AngularVelocity != (mgl64.Vec3{}) || p.ground.shape.heightfield != nil {
+		return false
+	}
+
+	normal, depth, ok := groundSeparation(b, p.ground)
+	if !ok {
+		return true
+	}
+	var point [1]manifoldPoint
+	c := contact{a: b, b: p.ground, normal: normal, depth: depth, feature: -1, solver: point[:]}
+	c.buildManifold()
+	c.prepare()
+	c.solveVelocity()
+	c.finish()
+	solvePosition(&c)
+	return true
+}
+
+// testStatic finds the contacts of a moving body with the ground and with the
+// bodies of the static hash
+func (p *Physics) testStatic(b *body, out *narrowphase) {
+	// Dynamic bodies rest on the ground, the terrain or the floor plane at y=0,
+	// unless they were already settled on the floor. Boxes touch the terrain
+	// with each of their corners.
+	switch {
+	case b.BodyType != BodyDynamic || b.Sensor || b.floored:
+	case b.shape.kind == ShapeBox && p.ground.shape.heightfield != nil:
+		out.contacts = terrainContacts(b, p.ground, out.contacts)
+	default:
+		out.addContact(b, p.ground)
+	}
+
+	if !b.collides() || p.staticBroadphase.empty() {
+		return
+	}
+	p.staticBroadphase.Query(b.bounds(), func(id int32) {
+		p.testPair(b, p.staticOwners[id], out)
+	})
+}
+
+// testPair checks two bodies for an overlap and adds it to the contacts of a
+// batch. Overlaps with a sensor are recorded as triggers instead and get no
+// collision response.
+func (p *Physics) testPair(a, b *body, out *narrowphase) {
+	if !a.collides() || !b.collides() || !a.interacts(b) {
+		return
+	}
+
+	// Meshes are always b, and are only tested against the other shapes
+	if a.shape.mesh != nil {
+		a, b = b, a
+	}
+	if a.shape.mesh != nil {
+		return
+	}
+
+	if a.Sensor || b.Sensor {
+		// Sensors do not detect each other
+		if a.Sensor == b.Sensor {
+			return
+		}
+		if overlapping(a, b) {
+			if a.Sensor {
+				out.triggers = append(out.triggers, [2]*body{a, b})
+			} else {
+				out.triggers = append(out.triggers, [2]*body{b, a})
+			}
+		}
+		return
+	}
+
+	// Static and kinematic bodies cannot push each other, and jointed bodies
+	// are held together by their joint instead
+	if (a.InverseMass == 0 && b.InverseMass == 0) || p.jointed(a, b) {
+		return
+	}
+	if b.shape.mesh != nil {
+		out.contacts = meshContacts(a, b, out.contacts)
+		return
+	}
+	if out.addContact(a, b) {
+		// Sleeping bodies are only found through the static hash, being touched wakes them up
+		if b.sleeping {
+			out.touched = append(out.touched, b)
+		}
+	}
+}
+
+// addContact adds the contact between two bodies with its manifold when they
+// overlap. The contact is built in place, as it is too large to copy around.
+func (out *narrowphase) addContact(a, b *body) bool {
+	normal, depth, ok := separation(a, b)
+	if !ok {
+		return false
+	}
+	out.contacts = slices.Grow(out.contacts, 1)[:len(out.contacts)+1]
+	c := &out.contacts[len(out.contacts)-1]
+	*c = contact{a: a, b: b, normal: normal, depth: depth, feature: -1}
+	c.buildManifold()
+	return true
+}
+
+// computeContact computes the overlap between two bodies at their current
+// positions, without the manifold
+func computeContact(a, b *body) (contact, bool) {
+	normal, depth, ok := separation(a, b)
+	if !ok {
+		return contact{}, false
+	}
+	return contact{a: a, b: b, normal: normal, depth: depth, feature: -1}, true
+}
+
+// overlapping reports whether two bodies overlap, where b may be a mesh
+func overlapping(a, b *body) bool {
+	if b.shape.mesh != nil {
+		return meshOverlaps(a, b)
+	}
+	_, _, ok := separation(a, b)
+	return ok
+}
+
+// separation measures the overlap of the bodies of a contact again, only
+// against the touched triangle for meshes and at the touching corner for boxes
+// on terrain
+func (c *contact) separation() (mgl64.Vec3, float64, bool) {
+	switch {
+	case c.feature >= 0 && c.b.ground:
+		return cornerSeparation(c.a.box().corners()[c.feature], c.b)
+	case c.feature >= 0:
+		return triangleSeparation(c.a, c.b, c.feature)
+	}
+	return separation(c.a, c.b)
+}
+
+// separation returns the direction and depth of the overlap between two bodies.
+// The normal points from a towards b along the axis of least penetration.
+func separation(a, b *body) (mgl64.Vec3, float64, bool) {
+	if b.ground {
+		return groundSeparation(a, b)
+	}
+	if a.shape.kind != ShapeBox || b.shape.kind != ShapeBox {
+		return shapeSeparation(a, b)
+	}
+	if !a.axisAligned || !b.axisAligned {
+		return orientedSeparation(a, b)
+	}
+
+	delta := b.Position.Sub(a.Position)
+
+	var normal mgl64.Vec3
+	depth := math.Inf(1)
+	for axis := 0; axis < 3; axis++ {
+		overlap := a.halfExtents[axis] + b.halfExtents[axis] - math.Abs(delta[axis])
+		if overlap <= 0 {
+			return mgl64.Vec3{}, 0, false
+		}
+
+		if overlap < depth {
+			depth = overlap
+			normal = mgl64.Vec3{}
+			if delta[axis] < 0 {
+				normal[axis] = -1
+			} else {
+				normal[axis] = 1
+			}
+		}
+	}
+
+	return normal, depth, true
+}
+
+// buildManifold finds the points where the bodies of a contact touch
+func (c *contact) buildManifold() {
+	switch {
+	case shortcuts && c.b.ground && c.a.shape.kind == ShapeBox && c.b.shape.heightfield == nil &&
+		c.a.axisAligned && c.a.AngularVelocity == (mgl64.Vec3{}):
+		// Flat on the floor plane, the bottom face is centered under the body
+		bottom := c.a.Position
+		bottom[1] -= c.a.halfExtents.Y()
+		c.addRestingPoint(bottom)
+	case c.b.ground && c.a.shape.kind == ShapeBox:
+		c.addLowestCorners()
+	case c.b.ground:
+		c.addShapeFeature()
+	case c.a.shape.kind != ShapeBox || c.b.shape.kind != ShapeBox:
+		shapeA, shapeB := c.a.convex(), c.b.convex()
+		c.addClippedFeatures(&shapeA, &shapeB)
+	case c.a.axisAligned && c.b.axisAligned:
+		c.addOverlapCorners()
+	default:
+		c.addTouchingCorners()
+	}
+}
+
+// addOverlapCorners uses the corners of the rectangle where two axis-aligned
+// boxes overlap, halfway through the overlap along the normal
+func (c *contact) addOverlapCorners() {
+	boundsA, boundsB := NewAABB(c.a.Position, c.a.halfExtents), NewAABB(c.b.Position, c.b.halfExtents)
+	lo, hi := maxVec(boundsA.Min, boundsB.Min), minVec(boundsA.Max, boundsB.Max)
+
+	axis := 0
+	for i := 1; i < 3; i++ {
+		if math.Abs(c.normal[i]) > math.Abs(c.normal[axis]) {
+			axis = i
+		}
+	}
+	u, v := (axis+1)%3, (axis+2)%3
+
+	if c.restingFace(lo, hi, u, v) {
+		var center mgl64.Vec3
+		center[axis] = (lo[axis] + hi[axis]) / 2
+		center[u], center[v] = (lo[u]+hi[u])/2, (lo[v]+hi[v])/2
+		c.addRestingPoint(center)
+		return
+	}
+
+	for i := 0; i < 4; i++ {
+		var point mgl64.Vec3
+		point[axis] = (lo[axis] + hi[axis]) / 2
+		point[u], point[v] = lo[u], lo[v]
+		if i&1 != 0 {
+			point[u] = hi[u]
+		}
+		if i&2 != 0 {
+			point[v] = hi[v]
+		}
+		c.addPoint(point)
+	}
+}
+
+// restingFace reports whether two axis-aligned boxes touching over the
+// rectangle lo to hi along the axes u and v rest flat on each other: neither of
+// them is spinning, and the center of each body that can turn is over the
+// rectangle. The four corners of such a contact would only ever share the load
+// without turning either body, so a single point at the center does the same
+// for a quarter of the work.
+func (c *contact) restingFace(lo, hi mgl64.Vec3, u, v int) bool {
+	if !shortcuts {
+		return false
+	}
+	for _, b := range [2]*body{c.a, c.b} {
+		if b.AngularVelocity != (mgl64.Vec3{}) {
+			return false
+		}
+		if b.inverseInertia == (mgl64.Vec3{}) {
+			continue
+		}
+		if b.Position[u] < lo[u] || b.Position[u] > hi[u] || b.Position[v] < lo[v] || b.Position[v] > hi[v] {
+			return false
+		}
+	}
+	return true
+}
+
+// addRestingPoint makes the manifold of a contact resting flat a single point
+// solved without turning either body. The normal of such a contact is one of
+// the world axes, so the other two are its friction directions.
+func (c *contact) addRestingPoint(position mgl64.Vec3) {
+	c.linear = true
+	c.distance = c.b.Position.Sub(c.a.Position).Dot(c.normal)
+	for axis := 0; axis < 3; axis++ {
+		if c.normal[axis] != 0 {
+			c.tangents[0][(axis+1)%3], c.tangents[1][(axis+2)%3] = 1, 1
+		}
+	}
+	c.addPoint(position)
+}
+
+// addPoint appends a point to the manifold of a contact
+func (c *contact) addPoint(position mgl64.Vec3) {
+	c.points[c.pointCount] = position
+	c.pointCount++
+}
+
+// centroid returns the average of the manifold points
+func (c *contact) centroid() mgl64.Vec3 {
+	var sum mgl64.Vec3
+	for i := 0; i < c.pointCount; i++ {
+		sum = sum.Add(c.points[i])
+	}
+	return sum.Mul(1 / float64(c.pointCount))
+}
+
+// prepare computes the effective masses and bounce targets of the manifold
+// points, using the velocities the bodies had before any impulse was applied
+func (c *contact) prepare() {
+	a, b := c.a, c.b
+	c.surface = combineMaterials(a.material, b.material)
+	if c.linear {
+		c.prepareLinear()
+		return
+	}
+	c.tangents = tangentBasis(c.normal)
+
+	for i := range c.solver {
+		point := &c.solver[i]
+		rA := c.points[i].Sub(a.Position)
+		rB := c.points[i].Sub(b.Position)
+		*point = manifoldPoint{
+			normal:   c.axis(rA, rB, c.normal),
+			tangents: [2]contactAxis{c.axis(rA, rB, c.tangents[0]), c.axis(rA, rB, c.tangents[1])},
+		}
+
+		// Slow impacts do not bounce, otherwise resting bodies would never settle
+		normalVelocity := b.velocityAt(rB).Sub(a.velocityAt(rA)).Dot(c.normal)
+		if normalVelocity < -restitutionThreshold {
+			point.bounce = -c.surface.restitution * normalVelocity
+		}
+	}
+}
+
+// prepareLinear is prepare for a contact resting flat, whose single point
+// turns neither body
+func (c *contact) prepareLinear() {
+	a, b, point := c.a, c.b, &c.solver[0]
+	mass := 1 / (a.InverseMass + b.InverseMass)
+
+	// Only the masses and impulses are used by solveLinear, the arms are left alone
+	point.bounce = 0
+	for _, axis := range [3]*contactAxis{&point.normal, &point.tangents[0], &point.tangents[1]} {
+		axis.mass, axis.impulse = mass, 0
+	}
+
+	var normalVelocity float64
+	for i := 0; i < 3; i++ {
+		normalVelocity += (b.Velocity[i] - a.Velocity[i]) * c.normal[i]
+	}
+	if normalVelocity < -restitutionThreshold {
+		point.bounce = -c.surface.restitution * normalVelocity
+	}
+}
+
+// axis builds the solver axis along direction for a point at offsets rA and rB
+// from the centers of both bodies
+func (c *contact) axis(rA, rB, direction mgl64.Vec3) contactAxis {
+	axis := contactAxis{armA: rA.Cross(direction), armB: rB.Cross(direction)}
+	axis.responseA = c.a.inverseInertiaWorld.Mul3x1(axis.armA)
+	axis.responseB = c.b.inverseInertiaWorld.Mul3x1(axis.armB)
+
+	inverse := c.a.InverseMass + c.b.InverseMass + axis.armA.Dot(axis.responseA) + axis.armB.Dot(axis.responseB)
+	if inverse > 0 {
+		axis.mass = 1 / inverse
+	}
+	return axis
+}
+
+// speed returns how fast the point of b moves away from the point of a along an axis
+func (c *contact) speed(axis *contactAxis, direction mgl64.Vec3) float64 {
+	a, b := c.a, c.b
+	return direction.Dot(b.Velocity) + axis.armB.Dot(b.AngularVelocity) -
+		direction.Dot(a.Velocity) - axis.armA.Dot(a.AngularVelocity)
+}
+
+// push applies an impulse along an axis of a contact, pushing the body along
+// the direction. The other body gets the opposite impulse. Static and
+// kinematic bodies do not respond to impulses and are left untouched, as
+// several regions may be pushing against them at once.
+func (b *body) push(direction, response mgl64.Vec3, impulse float64) {
+	if b.InverseMass == 0 {
+		return
+	}
+	for i := 0; i < 3; i++ {
+		b.Velocity[i] += direction[i] * impulse * b.InverseMass
+		b.AngularVelocity[i] += response[i] * impulse
+	}
+}
+
+// solveVelocity applies the impulses that stop the bodies of a contact from
+// moving into each other, bouncing and sliding according to their materials.
+// Impulses are accumulated over the solver iterations and clamped, so the
+// points of a resting face share the load evenly.
+func (c *contact) solveVelocity() {
+	if c.linear {
+		c.solveLinear()
+		return
+	}
+
+	for i := range c.solver {
+		point := &c.solver[i]
+
+		// Only ever push the bodies apart
+		normal := &point.normal
+		previous := normal.impulse
+		normal.impulse = math.Max(previous+normal.mass*(point.bounce-c.speed(normal, c.normal)), 0)
+		c.a.push(c.normal, normal.responseA, previous-normal.impulse)
+		c.b.push(c.normal, normal.responseB, normal.impulse-previous)
+
+		// Friction opposes the sliding between both surfaces, bounded by the normal impulse
+		first, second := &point.tangents[0], &point.tangents[1]
+		previousFirst, previousSecond := first.impulse, second.impulse
+		first.impulse -= c.speed(first, c.tangents[0]) * first.mass
+		second.impulse -= c.speed(second, c.tangents[1]) * second.mass
+		if length := math.Sqrt(first.impulse*first.impulse + second.impulse*second.impulse); length > c.surface.staticFriction*normal.impulse {
+			scale := c.surface.dynamicFriction * normal.impulse / length
+			first.impulse *= scale
+			second.impulse *= scale
+		}
+		c.a.push(c.tangents[0], first.responseA, previousFirst-first.impulse)
+		c.b.push(c.tangents[0], first.responseB, first.impulse-previousFirst)
+		c.a.push(c.tangents[1], second.responseA, previousSecond-second.impulse)
+		c.b.push(c.tangents[1], second.responseB, second.impulse-previousSecond)
+	}
+}
+
+// solveLinear is solveVelocity for a contact resting flat, whose single point
+// turns neither body. Like the other hot paths of a step it works on the
+// components of the vectors: the compiler keeps mgl64 vectors in memory, which
+// makes their methods slow enough here to miss the tick budget of 10k bodies.
+func (c *contact) solveLinear() {
+	a, b, point := c.a, c.b, &c.solver[0]
+	var normalSpeed, firstSpeed, secondSpeed float64
+	for i := 0; i < 3; i++ {
+		relative := b.Velocity[i] - a.Velocity[i]
+		normalSpeed += relative * c.normal[i]
+		firstSpeed += relative * c.tangents[0][i]
+		secondSpeed += relative * c.tangents[1][i]
+	}
+
+	// Only ever push the bodies apart
+	normal := &point.normal
+	previous := normal.impulse
+	normal.impulse += normal.mass * (point.bounce - normalSpeed)
+	if normal.impulse < 0 {
+		normal.impulse = 0
+	}
+
+	// Friction opposes the sliding between both surfaces, bounded by the normal impulse
+	first, second := &point.tangents[0], &point.tangents[1]
+	previousFirst, previousSecond := first.impulse, second.impulse
+	first.impulse -= firstSpeed * first.mass
+	second.impulse -= secondSpeed * second.mass
+	limit := c.surface.staticFriction * normal.impulse
+	if squared := first.impulse*first.impulse + second.impulse*second.impulse; squared > limit*limit {
+		scale := c.surface.dynamicFriction * normal.impulse / math.Sqrt(squared)
+		first.impulse *= scale
+		second.impulse *= scale
+	}
+
+	var change mgl64.Vec3
+	n, f, s := normal.impulse-previous, first.impulse-previousFirst, second.impulse-previousSecond
+	for i := 0; i < 3; i++ {
+		change[i] = c.normal[i]*n + c.tangents[0][i]*f + c.tangents[1][i]*s
+	}
+	if a.InverseMass != 0 {
+		for i := 0; i < 3; i++ {
+			a.Velocity[i] -= change[i] * a.InverseMass
+		}
+	}
+	if b.InverseMass != 0 {
+		for i := 0; i < 3; i++ {
+			b.Velocity[i] += change[i] * b.InverseMass
+		}
+	}
+}
+
+// finish totals the impulse of a contact once its velocities are solved and
+// marks the body resting on the other one as grounded
+func (c *contact) finish() {
+	c.impulse = 0
+	for i := range c.solver {
+		c.impulse += c.solver[i].normal.impulse
+	}
+
+	// A contact normal pointing down means the other body is supporting this one
+	if c.normal.Y() < -0.7 {
+		c.a.grounded = true
+	}
+	if c.normal.Y() > 0.7 {
+		c.b.grounded = true
+	}
+}
+
+// solvePosition pushes two overlapping bodies apart. The correction is shared
+// in proportion to the inverse masses, so lighter bodies are pushed further
+// than heavier ones.
+func solvePosition(c *contact) {
+	a, b := c.a, c.b
+	inverseMassSum := a.InverseMass + b.InverseMass
+	if inverseMassSum == 0 {
+		return
+	}
+
+	// Positions may have changed since the contact was found, so measure it
+	// again. Bodies resting flat only ever move along the fixed normal, which
+	// changes the depth by as much as the distance between their centers.
+	normal, depth, ok := c.normal, c.depth+c.distance, true
+	if c.linear {
+		for i := 0; i < 3; i++ {
+			depth -= (b.Position[i] - a.Position[i]) * normal[i]
+		}
+	} else {
+		normal, depth, ok = c.separation()
+	}
+	if !ok {
+		return
+	}
+
+	if correction := depth - penetrationSlop; correction > 0 {
+		scale := correction / inverseMassSum
+		if a.InverseMass != 0 {
+			for i := 0; i < 3; i++ {
+				a.Position[i] -= normal[i] * scale * a.InverseMass
+			}
+		}
+		if b.InverseMass != 0 {
+			for i := 0; i < 3; i++ {
+				b.Position[i] += normal[i] * scale * b.InverseMass
+			}
+		}
+	}
+}
+
+// tangentBasis returns two unit directions perpendicular to a normal and to each other
+func tangentBasis(normal mgl64.Vec3) [2]mgl64.Vec3 {
+	reference := mgl64.Vec3{1, 0, 0}
+	if math.Abs(normal.X()) > 0.57 {
+		reference = mgl64.Vec3{0, 1, 0}
+	}
+	first := normal.Cross(reference).Normalize()
+	return [2]mgl64.Vec3{first, normal.Cross(first)}
+}
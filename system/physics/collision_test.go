@@ -0,0 +1,239 @@
+package physics
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/anthdm/hollywood/actor"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+func newTestPhysics() *Physics {
+	p := &Physics{}
+	p.init()
+	return p
+}
+
+func TestStackedCubesComeToRest(t *testing.T) {
+	p := newTestPhysics()
+
+	bottom := actor.NewPID("local", "bottom")
+	top := actor.NewPID("local", "top")
+	p.Register(bottom, EntityRigidBody{Position: mgl64.Vec3{0, 0.5, 0}, Scale: mgl64.Vec3{1, 1, 1}, EntityType: "cube"})
+	p.Register(top, EntityRigidBody{Position: mgl64.Vec3{0.2, 3, 0}, Scale: mgl64.Vec3{1, 1, 1}, EntityType: "cube"})
+
+	for i := 0; i < 256*5; i++ {
+		p.Step(1.0 / 256)
+	}
+
+	if y := p.entities[bottom].Position.Y(); y < 0.45 || y > 0.55 {
+		t.Errorf("bottom cube should rest on the floor, got y=%f", y)
+	}
+	if y := p.entities[top].Position.Y(); y < 1.45 || y > 1.55 {
+		t.Errorf("top cube should rest on the bottom cube, got y=%f", y)
+	}
+	if !p.entities[top].grounded {
+		t.Error("top cube should be supported by the bottom cube")
+	}
+}
+
+func TestFloorFastPathMatchesSolver(t *testing.T) {
+	pid := actor.NewPID("local", "crate")
+	worlds := [2]*Physics{newTestPhysics(), newTestPhysics()}
+	for _, p := range worlds {
+		p.Register(pid, EntityRigidBody{Position: mgl64.Vec3{0, 1, 0}, Velocity: mgl64.Vec3{3, -2, 1}, Scale: mgl64.Vec3{1, 1, 1}})
+	}
+
+	// The second world leaves the floor to the narrowphase and the solver
+	for i := 0; i < 256; i++ {
+		worlds[1].entities[pid].touching = true
+		for _, p := range worlds {
+			p.Step(1.0 / 256)
+		}
+
+		fast, solved := worlds[0].entities[pid], worlds[1].entities[pid]
+		if fast.Position != solved.Position || fast.Velocity != solved.Velocity || fast.grounded != solved.grounded {
+			t.Fatalf("step %d: settled on the floor at %v moving %v, solved at %v moving %v", i, fast.Position, fast.Velocity, solved.Position, solved.Velocity)
+		}
+	}
+	if !worlds[0].entities[pid].floored {
+		t.Error("a crate alone on the floor should be settled while integrated")
+	}
+}
+
+func TestRestingShortcutsMatchFullManifolds(t *testing.T) {
+	// Full manifolds solve the corners one after the other, which lets stacks
+	// wobble a little before they settle, so they only end up close to the
+	// bodies resting on a single point
+	tests := []struct {
+		name      string
+		bodies    []EntityRigidBody
+		tolerance float64 // Distance between the resting positions, and angle between the orientations
+	}{
+		{
+			name:      "crate sliding to a stop on the floor",
+			bodies:    []EntityRigidBody{{Position: mgl64.Vec3{0, 0.5, 0}, Velocity: mgl64.Vec3{3, 0, 1}, Scale: mgl64.Vec3{1, 1, 1}}},
+			tolerance: 1e-3,
+		},
+		{
+			name: "crates stacked and dropped on a wide base",
+			bodies: []EntityRigidBody{
+				{Position: mgl64.Vec3{0, 0.5, 0}, Scale: mgl64.Vec3{2, 1, 2}},
+				{Position: mgl64.Vec3{0, 1.5, 0}, Scale: mgl64.Vec3{1, 1, 1}},
+				{Position: mgl64.Vec3{0.1, 3, 0.1}, Velocity: mgl64.Vec3{0, -2, 0}, Scale: mgl64.Vec3{1, 1, 1}},
+			},
+			tolerance: 0.05,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pids := make([]*actor.PID, len(tt.bodies))
+			for i := range pids {
+				pids[i] = actor.NewPID("local", fmt.Sprintf("crate_%d", i))
+			}
+
+			// The second world solves every contact with all of its corners
+			worlds := [2]*Physics{newTestPhysics(), newTestPhysics()}
+			for _, p := range worlds {
+				for i, entity := range tt.bodies {
+					p.Register(pids[i], entity)
+				}
+			}
+			for i := 0; i < 512; i++ {
+				worlds[0].Step(1.0 / 256)
+				shortcuts = false
+				worlds[1].Step(1.0 / 256)
+				shortcuts = true
+			}
+
+			for i, pid := range pids {
+				fast, full := worlds[0].entities[pid], worlds[1].entities[pid]
+				if distance := fast.Position.Sub(full.Position).Len(); distance > tt.tolerance || fast.Velocity != full.Velocity {
+					t.Errorf("crate %d: resting on a single point at %v moving %v, on all corners at %v moving %v", i, fast.Position, fast.Velocity, full.Position, full.Velocity)
+				}
+				turn := fast.Orientation.Inverse().Mul(full.Orientation).Normalize()
+				if angle := 2 * math.Acos(math.Min(math.Abs(turn.W), 1)); angle > tt.tolerance {
+					t.Errorf("crate %d: turned by %f between %v resting on a single point and %v on all corners", i, angle, fast.Orientation, full.Orientation)
+				}
+			}
+		})
+	}
+}
+
+func TestComputeContactUsesLeastPenetrationAxis(t *testing.T) {
+	p := newTestPhysics()
+
+	a := actor.NewPID("local", "a")
+	b := actor.NewPID("local", "b")
+	p.Register(a, EntityRigidBody{Position: mgl64.Vec3{0, 0, 0}, Scale: mgl64.Vec3{1, 1, 1}})
+	p.Register(b, EntityRigidBody{Position: mgl64.Vec3{0.9, 0.2, 0}, Scale: mgl64.Vec3{1, 1, 1}})
+
+	c, ok := computeContact(p.entities[a], p.entities[b])
+	if !ok {
+		t.Fatal("expected overlapping boxes to produce a contact")
+	}
+	if c.normal != (mgl64.Vec3{1, 0, 0}) {
+		t.Errorf("expected +X normal, got %v", c.normal)
+	}
+	if c.depth < 0.099 || c.depth > 0.101 {
+		t.Errorf("expected depth 0.1, got %f", c.depth)
+	}
+}
+
+func TestStaticBodiesHoldAndKinematicBodiesPush(t *testing.T) {
+	p := newTestPhysics()
+
+	ledge := actor.NewPID("local", "ledge")
+	crate := actor.NewPID("local", "crate")
+	pusher := actor.NewPID("local", "pusher")
+	p.Register(ledge, EntityRigidBody{Position: mgl64.Vec3{0, 2, 0}, Scale: mgl64.Vec3{4, 1, 4}, BodyType: BodyStatic})
+	p.Register(crate, EntityRigidBody{Position: mgl64.Vec3{0, 3.5, 0}, Scale: mgl64.Vec3{1, 1, 1}})
+	p.Register(pusher, EntityRigidBody{Position: mgl64.Vec3{-2, 3.5, 0}, Scale: mgl64.Vec3{1, 1, 1}, BodyType: BodyKinematic})
+
+	for i := 0; i < 256; i++ {
+		p.Step(1.0 / 256)
+	}
+
+	if p.entities[ledge].Position != (mgl64.Vec3{0, 2, 0}) {
+		t.Errorf("static body should never move, got %v", p.entities[ledge].Position)
+	}
+	if y := p.entities[crate].Position.Y(); y < 2.95 || y > 3.05 {
+		t.Errorf("crate should rest on the static ledge, got y=%f", y)
+	}
+
+	// Drive the kinematic body through the crate, it must not be slowed down
+	p.entities[pusher].Velocity = mgl64.Vec3{0.7, 0, 0}
+	for i := 0; i < 256; i++ {
+		p.Step(1.0 / 256)
+	}
+
+	if x := p.entities[pusher].Position.X(); math.Abs(x-(-2+0.7)) > 1e-9 {
+		t.Errorf("kinematic body should follow its velocity, got x=%f", x)
+	}
+	if y := p.entities[pusher].Position.Y(); y != 3.5 {
+		t.Errorf("kinematic body should ignore gravity, got y=%f", y)
+	}
+	if x := p.entities[crate].Position.X(); x < p.entities[pusher].Position.X()+0.99 {
+		t.Errorf("crate should be pushed ahead of the kinematic body, got x=%f", x)
+	}
+}
+
+func TestTiltedBoxTopplesOntoAFace(t *testing.T) {
+	p := newTestPhysics()
+
+	crate := actor.NewPID("local", "crate")
+	tilt := mgl64.QuatRotate(math.Pi/5, mgl64.Vec3{0, 0, 1})
+	p.Register(crate, EntityRigidBody{Position: mgl64.Vec3{0, 1.5, 0}, Scale: mgl64.Vec3{1, 1, 1}, Orientation: tilt})
+
+	spun := false
+	for i := 0; i < 256*4; i++ {
+		p.Step(FixedDeltaTime)
+		spun = spun || p.entities[crate].AngularVelocity.Len() > 0.5
+	}
+
+	if !spun {
+		t.Error("landing on a corner should make the box rotate")
+	}
+
+	// Resting on a face, one of the local axes points straight up
+	b := p.entities[crate]
+	up := 0.0
+	for axis := 0; axis < 3; axis++ {
+		up = math.Max(up, math.Abs(b.rotation.Col(axis).Y()))
+	}
+	if up < 0.99 {
+		t.Errorf("box should settle on one of its faces, best axis alignment %f", up)
+	}
+	if y := b.Position.Y(); y < 0.45 || y > 0.55 {
+		t.Errorf("box should rest on the floor, got y=%f", y)
+	}
+}
+
+func TestOrientedContactFindsRotatedOverlap(t *testing.T) {
+	p := newTestPhysics()
+
+	a := actor.NewPID("local", "a")
+	b := actor.NewPID("local", "b")
+	diamond := mgl64.QuatRotate(math.Pi/4, mgl64.Vec3{0, 0, 1})
+	p.Register(a, EntityRigidBody{Position: mgl64.Vec3{0, 0, 0}, Scale: mgl64.Vec3{1, 1, 1}})
+	p.Register(b, EntityRigidBody{Position: mgl64.Vec3{1.15, 0, 0}, Scale: mgl64.Vec3{1, 1, 1}, Orientation: diamond})
+
+	// The corner of the diamond reaches 0.5*sqrt(2) towards the cube
+	c, ok := computeContact(p.entities[a], p.entities[b])
+	if !ok {
+		t.Fatal("expected the corner of the rotated box to overlap the cube")
+	}
+	if c.normal.Sub(mgl64.Vec3{1, 0, 0}).Len() > 1e-9 {
+		t.Errorf("expected +X normal, got %v", c.normal)
+	}
+	if expected := 0.5 + math.Sqrt2/2 - 1.15; math.Abs(c.depth-expected) > 1e-9 {
+		t.Errorf("expected depth %f, got %f", expected, c.depth)
+	}
+
+	p.entities[b].Position = mgl64.Vec3{1.25, 0, 0}
+	if _, ok := computeContact(p.entities[a], p.entities[b]); ok {
+		t.Error("separated boxes should not produce a contact")
+	}
+}
@@ -0,0 +1,94 @@
+package physics
+
+import (
+	"github.com/anthdm/hollywood/actor"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// pairKey identifies a pair of touching bodies, ordered by body id so that the
+// same pair always produces the same key
+type pairKey struct {
+	a, b uint32
+}
+
+// trackedContact is a contact pair followed across ticks to report when it
+// starts and stops touching
+type trackedContact struct {
+	a, b     *body
+	point    mgl64.Vec3 // Center of the manifold found in the latest step
+	normal   mgl64.Vec3 // Points from a towards b
+	impulse  float64    // Normal impulse exchanged during the current tick
+	touching bool       // Found during one of the steps of the current tick
+	started  bool       // Not reported yet
+}
+
+// makePairKey returns the key of the pair formed by two bodies
+func makePairKey(a, b *body) pairKey {
+	if a.id > b.id {
+		a, b = b, a
+	}
+	return pairKey{a.id, b.id}
+}
+
+// recordContacts remembers the contacts resolved during a step. Pairs touching
+// in several steps of a tick are reported once with their latest contact point
+// and the sum of their impulses.
+func (p *Physics) recordContacts(contacts []contact) {
+	for i := range contacts {
+		c := &contacts[i]
+		// The floor plane has no owner to notify
+		if c.b.ground {
+			continue
+		}
+
+		key := makePairKey(c.a, c.b)
+		tracked, ok := p.contacts[key]
+		if !ok {
+			tracked = &trackedContact{a: c.a, b: c.b, started: true}
+			p.contacts[key] = tracked
+		}
+
+		tracked.touching = true
+		tracked.point = c.centroid()
+		tracked.normal = c.normal
+		if c.a != tracked.a {
+			tracked.normal = c.normal.Mul(-1)
+		}
+		tracked.impulse += c.impulse
+	}
+}
+
+// contactEvents reports the contact pairs of the last tick to both bodies: an
+// enter event when the pair started touching, a stay event while it keeps
+// touching and an exit event once it separated. Pairs of sleeping bodies are
+// not reported until they wake up.
+func (p *Physics) contactEvents(send func(pid *actor.PID, event any)) {
+	for _, key := range pairOrder(p.contacts, p.deterministic) {
+		tracked := p.contacts[key]
+		// Pairs left out of the simulation keep touching without being reported
+		if !tracked.touching && tracked.a.dormant() && tracked.b.dormant() {
+			continue
+		}
+
+		collisionA := Collision{Other: tracked.b.pid, Point: tracked.point, Normal: tracked.normal, Impulse: tracked.impulse}
+		collisionB := Collision{Other: tracked.a.pid, Point: tracked.point, Normal: tracked.normal.Mul(-1), Impulse: tracked.impulse}
+
+		switch {
+		case !tracked.touching:
+			send(tracked.a.pid, EventCollisionExit{PID: tracked.a.pid, Collision: collisionA})
+			send(tracked.b.pid, EventCollisionExit{PID: tracked.b.pid, Collision: collisionB})
+			delete(p.contacts, key)
+			continue
+		case tracked.started:
+			send(tracked.a.pid, EventCollisionEnter{PID: tracked.a.pid, Collision: collisionA})
+			send(tracked.b.pid, EventCollisionEnter{PID: tracked.b.pid, Collision: collisionB})
+			tracked.started = false
+		default:
+			send(tracked.a.pid, EventCollisionStay{PID: tracked.a.pid, Collision: collisionA})
+			send(tracked.b.pid, EventCollisionStay{PID: tracked.b.pid, Collision: collisionB})
+		}
+
+		tracked.touching = false
+		tracked.impulse = 0
+	}
+}
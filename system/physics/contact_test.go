@@ -0,0 +1,70 @@
+package physics
+
+import (
+	"testing"
+
+	"github.com/anthdm/hollywood/actor"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// collectContactEvents steps one tick and returns the contact events sent to pid
+func collectContactEvents(p *Physics, pid *actor.PID) []any {
+	var events []any
+	p.Advance(FixedDeltaTime)
+	p.contactEvents(func(to *actor.PID, event any) {
+		if to == pid {
+			events = append(events, event)
+		}
+	})
+	return events
+}
+
+func TestContactEventsFollowPairAcrossTicks(t *testing.T) {
+	p := newTestPhysics()
+
+	ledge := actor.NewPID("local", "ledge")
+	crate := actor.NewPID("local", "crate")
+	p.Register(ledge, EntityRigidBody{Position: mgl64.Vec3{0, 2, 0}, Scale: mgl64.Vec3{4, 1, 4}, BodyType: BodyStatic})
+	p.Register(crate, EntityRigidBody{Position: mgl64.Vec3{0, 3.6, 0}, Scale: mgl64.Vec3{1, 1, 1}})
+
+	var enter *EventCollisionEnter
+	for i := 0; i < 256 && enter == nil; i++ {
+		for _, event := range collectContactEvents(p, crate) {
+			if e, ok := event.(EventCollisionEnter); ok {
+				enter = &e
+			}
+		}
+	}
+	if enter == nil {
+		t.Fatal("expected the crate to enter a collision with the ledge")
+	}
+	if enter.Other != ledge || enter.Normal != (mgl64.Vec3{0, -1, 0}) || enter.Impulse <= 0 {
+		t.Errorf("unexpected enter event %+v", *enter)
+	}
+
+	// Let the crate settle after bouncing, without giving it time to fall asleep
+	for i := 0; i < 64; i++ {
+		collectContactEvents(p, crate)
+	}
+
+	events := collectContactEvents(p, crate)
+	if len(events) != 1 {
+		t.Fatalf("expected a single stay event, got %v", events)
+	}
+	if _, ok := events[0].(EventCollisionStay); !ok {
+		t.Errorf("expected a stay event, got %T", events[0])
+	}
+
+	// Lift the crate off the ledge
+	p.entities[crate].Position = mgl64.Vec3{0, 10, 0}
+	events = collectContactEvents(p, crate)
+	if len(events) != 1 {
+		t.Fatalf("expected a single exit event, got %v", events)
+	}
+	if exit, ok := events[0].(EventCollisionExit); !ok || exit.Other != ledge {
+		t.Errorf("expected an exit event from the ledge, got %+v", events[0])
+	}
+	if len(p.contacts) != 0 {
+		t.Errorf("separated pairs should no longer be tracked, got %d", len(p.contacts))
+	}
+}
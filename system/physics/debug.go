@@ -0,0 +1,161 @@
+package physics
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+const (
+	// debugRingSegments is how many segments the outlines of round shapes and
+	// convex hulls are drawn with
+	debugRingSegments = 16
+
+	// debugNormalLength is the length of the contact normals drawn
+	debugNormalLength = 0.5
+
+	// debugVelocityScale turns a velocity into the length of its line, the
+	// distance the body covers in a tenth of a second
+	debugVelocityScale = 0.1
+)
+
+// DebugDraw selects the debug geometry the physics system produces
+type DebugDraw uint32
+
+const (
+	// DebugColliders draws the wireframe of every collider, colored by the
+	// state of its body
+	DebugColliders DebugDraw = 1 << iota
+	// DebugContacts draws the normal of the contacts found by the last step at
+	// every manifold point, pointing from the first body towards the second
+	DebugContacts
+	// DebugAABBs draws the broadphase bounds of every body
+	DebugAABBs
+	// DebugVelocities draws the linear velocity of every moving body
+	DebugVelocities
+)
+
+// Colors of the debug geometry, colliders are colored by the state of their body
+var (
+	debugColorDynamic   = mgl64.Vec3{0.2, 1, 0.2}
+	debugColorSleeping  = mgl64.Vec3{0.3, 0.5, 1}
+	debugColorKinematic = mgl64.Vec3{1, 0.9, 0.2}
+	debugColorStatic    = mgl64.Vec3{0.6, 0.6, 0.6}
+	debugColorSensor    = mgl64.Vec3{1, 0.3, 1}
+	debugColorContact   = mgl64.Vec3{1, 0.2, 0.2}
+	debugColorAABB      = mgl64.Vec3{1, 0.6, 0.1}
+	debugColorVelocity  = mgl64.Vec3{0.2, 1, 1}
+)
+
+// DebugLine is a colored segment of the debug geometry, in world space
+type DebugLine struct {
+	From, To mgl64.Vec3
+	Color    mgl64.Vec3
+}
+
+// debugGeometry builds the lines selected by the debug draw flags for the
+// current state of the world. Terrain is drawn by the renderer and skipped.
+func (p *Physics) debugGeometry() []DebugLine {
+	var lines []DebugLine
+	add := func(from, to, color mgl64.Vec3) {
+		lines = append(lines, DebugLine{From: from, To: to, Color: color})
+	}
+
+	for _, b := range p.bodies {
+		if b.shape.heightfield != nil {
+			continue
+		}
+		if p.debugDraw&DebugColliders != 0 {
+			b.debugWireframe(add)
+		}
+		if p.debugDraw&DebugAABBs != 0 {
+			bounds := b.bounds()
+			extents := bounds.Max.Sub(bounds.Min).Mul(0.5)
+			debugBox(orientedBox{
+				center: bounds.Center(),
+				axes:   [3]mgl64.Vec3{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}},
+				half:   extents,
+			}, debugColorAABB, add)
+		}
+		if p.debugDraw&DebugVelocities != 0 && !b.dormant() && b.Velocity != (mgl64.Vec3{}) {
+			add(b.Position, b.Position.Add(b.Velocity.Mul(debugVelocityScale)), debugColorVelocity)
+		}
+	}
+
+	if p.debugDraw&DebugContacts != 0 {
+		for i := range p.stepContacts {
+			c := &p.stepContacts[i]
+			for j := 0; j < c.pointCount; j++ {
+				point := c.points[j]
+				add(point, point.Add(c.normal.Mul(debugNormalLength)), debugColorContact)
+			}
+		}
+
+		// Boxes settled on the floor while integrated touch it under their center
+		for _, b := range p.active {
+			point := b.Position.Sub(mgl64.Vec3{0, b.halfExtents.Y(), 0})
+			if b.floored && point.Y() < 0 {
+				add(point, point.Add(mgl64.Vec3{0, -debugNormalLength, 0}), debugColorContact)
+			}
+		}
+	}
+
+	return lines
+}
+
+// debugColor returns the color of the collider of a body
+func (b *body) debugColor() mgl64.Vec3 {
+	switch {
+	case b.Sensor:
+		return debugColorSensor
+	case b.sleeping:
+		return debugColorSleeping
+	case b.BodyType == BodyStatic:
+		return debugColorStatic
+	case b.BodyType == BodyKinematic:
+		return debugColorKinematic
+	}
+	return debugColorDynamic
+}
+
+// debugWireframe draws the collider of a body. Boxes and triangle meshes are
+// drawn with their edges, other shapes with their outline around each of
+// their local axes.
+func (b *body) debugWireframe(add func(from, to, color mgl64.Vec3)) {
+	color := b.debugColor()
+	switch {
+	case b.shape.mesh != nil:
+		for _, triangle := range b.shape.mesh.triangles {
+			add(triangle[0], triangle[1], color)
+			add(triangle[1], triangle[2], color)
+			add(triangle[2], triangle[0], color)
+		}
+	case b.shape.kind == ShapeBox:
+		debugBox(b.box(), color, add)
+	default:
+		shape := b.convex()
+		for axis := 0; axis < 3; axis++ {
+			u, v := b.rotation.Col((axis+1)%3), b.rotation.Col((axis+2)%3)
+			previous := shape.support(u)
+			for i := 1; i <= debugRingSegments; i++ {
+				angle := 2 * math.Pi * float64(i) / debugRingSegments
+				point := shape.support(u.Mul(math.Cos(angle)).Add(v.Mul(math.Sin(angle))))
+				add(previous, point, color)
+				previous = point
+			}
+		}
+	}
+}
+
+// debugBox draws the twelve edges of a box
+func debugBox(box orientedBox, color mgl64.Vec3, add func(from, to, color mgl64.Vec3)) {
+	corners := box.corners()
+	for i := range corners {
+		for axis := 0; axis < 3; axis++ {
+			// Every edge joins two corners differing along one axis, drawn from the lower one
+			if i&(1<<axis) == 0 {
+				add(corners[i], corners[i|1<<axis], color)
+			}
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package physics
+
+import (
+	"testing"
+
+	"github.com/anthdm/hollywood/actor"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// countColors counts the debug lines of every color
+func countColors(lines []DebugLine) map[mgl64.Vec3]int {
+	counts := make(map[mgl64.Vec3]int)
+	for _, line := range lines {
+		counts[line.Color]++
+	}
+	return counts
+}
+
+func TestDebugGeometryFollowsTheFlagsAndSleepState(t *testing.T) {
+	p := newTestPhysics()
+	p.Register(actor.NewPID("local", "crate"), EntityRigidBody{Position: mgl64.Vec3{0, 0.5, 0}, Scale: mgl64.Vec3{1, 1, 1}, Velocity: mgl64.Vec3{2, 0, 0}})
+	p.Register(actor.NewPID("local", "ball"), EntityRigidBody{Position: mgl64.Vec3{4, 0.5, 0}, Scale: mgl64.Vec3{1, 1, 1}, Shape: SphereShape(0.5), BodyType: BodyStatic})
+
+	if lines := p.debugGeometry(); len(lines) != 0 {
+		t.Fatalf("nothing should be drawn without flags, got %d lines", len(lines))
+	}
+
+	p.debugDraw = DebugColliders | DebugContacts | DebugAABBs | DebugVelocities
+	p.Step(FixedDeltaTime)
+	counts := countColors(p.debugGeometry())
+
+	// A box is drawn with its 12 edges, the ball with three rings
+	if counts[debugColorDynamic] != 12 || counts[debugColorStatic] != 3*debugRingSegments {
+		t.Errorf("expected the edges of the crate and the rings of the ball, got %v", counts)
+	}
+	if counts[debugColorAABB] != 2*12 || counts[debugColorVelocity] != 1 || counts[debugColorContact] == 0 {
+		t.Errorf("expected both AABBs, the velocity of the crate and its contacts with the floor, got %v", counts)
+	}
+
+	// Once asleep the crate changes color and stops drawing its velocity
+	for i := 0; i < 256*2; i++ {
+		p.Step(FixedDeltaTime)
+	}
+	p.debugDraw = DebugColliders | DebugVelocities
+	counts = countColors(p.debugGeometry())
+	if counts[debugColorSleeping] != 12 || counts[debugColorDynamic] != 0 || counts[debugColorVelocity] != 0 {
+		t.Errorf("a sleeping crate should be drawn in the sleeping color only, got %v", counts)
+	}
+}
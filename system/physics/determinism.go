@@ -0,0 +1,133 @@
+package physics
+
+import (
+	"cmp"
+	"encoding/binary"
+	"hash/fnv"
+	"math"
+	"slices"
+
+	"github.com/anthdm/hollywood/actor"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// stateHashHistory is how many past ticks keep their state hash in
+// deterministic mode
+const stateHashHistory = 1024
+
+// stateHash is the hash of the state of every body after a tick
+type stateHash struct {
+	tick uint64
+	hash uint64
+}
+
+// NewDeterministic creates a physics system that simulates bit-identically on
+// every run given the same commands: every tick advances by exactly one step
+// of FixedDeltaTime whatever the wall clock says, commands are applied at the
+// start of the next tick with consecutive registrations sorted by PID, and
+// events are sent in the order of the bodies involved. The hash of every tick
+// is kept for RequestStateHash.
+func NewDeterministic() actor.Producer {
+	return func() actor.Receiver {
+		return &Physics{deterministic: true}
+	}
+}
+
+// applyPending applies the commands received since the last tick in the order
+// they arrived, except that registrations received one after the other are
+// sorted by PID, so bodies get the same order whichever actor got its
+// registration in first. Registrations never move past another command, which
+// may depend on them or be overridden by them.
+func (p *Physics) applyPending() {
+	pid := func(msg any) (string, bool) {
+		register, ok := msg.(EventRigidBodyRegister)
+		if !ok {
+			return "", false
+		}
+		return register.PID.String(), true
+	}
+	for start := 0; start < len(p.pending); {
+		end := start
+		for end < len(p.pending) {
+			if _, ok := pid(p.pending[end]); !ok {
+				break
+			}
+			end++
+		}
+		slices.SortStableFunc(p.pending[start:end], func(a, b any) int {
+			pidA, _ := pid(a)
+			pidB, _ := pid(b)
+			return cmp.Compare(pidA, pidB)
+		})
+		start = end + 1
+	}
+
+	for _, msg := range p.pending {
+		p.apply(msg)
+	}
+	clear(p.pending)
+	p.pending = p.pending[:0]
+}
+
+// recordStateHash remembers the hash of the state reached by the last step
+func (p *Physics) recordStateHash() {
+	p.hashes[p.steps%stateHashHistory] = stateHash{tick: p.steps, hash: p.StateHash()}
+}
+
+// TickStateHash returns the state hash recorded after a tick in deterministic
+// mode, as long as the tick is among the last stateHashHistory ones
+func (p *Physics) TickStateHash(tick uint64) (uint64, bool) {
+	recorded := p.hashes[tick%stateHashHistory]
+	if tick == 0 || recorded.tick != tick {
+		return 0, false
+	}
+	return recorded.hash, true
+}
+
+// StateHash returns a hash of the current state of every body: its transform,
+// its velocities and whether it sleeps. Bodies are hashed in registration
+// order, so two runs only match if they registered the same bodies in the
+// same order.
+func (p *Physics) StateHash() uint64 {
+	hash := fnv.New64a()
+	var buffer [8]byte
+	writeFloat := func(value float64) {
+		binary.LittleEndian.PutUint64(buffer[:], math.Float64bits(value))
+		hash.Write(buffer[:])
+	}
+	writeVec := func(v mgl64.Vec3) {
+		writeFloat(v.X())
+		writeFloat(v.Y())
+		writeFloat(v.Z())
+	}
+
+	for _, b := range p.bodies {
+		hash.Write([]byte(b.pid.String()))
+		writeVec(b.Position)
+		writeVec(b.Velocity)
+		writeFloat(b.Orientation.W)
+		writeVec(b.Orientation.V)
+		writeVec(b.AngularVelocity)
+		if b.sleeping {
+			hash.Write([]byte{1})
+		} else {
+			hash.Write([]byte{0})
+		}
+	}
+	return hash.Sum64()
+}
+
+// pairOrder returns the keys of a map of body pairs. They are sorted in
+// deterministic mode, so events about pairs go out in the same order every run.
+func pairOrder[V any](pairs map[pairKey]V, sorted bool) []pairKey {
+	keys := make([]pairKey, 0, len(pairs))
+	for key := range pairs {
+		keys = append(keys, key)
+	}
+	if sorted {
+		slices.SortFunc(keys, func(a, b pairKey) int {
+			return cmp.Or(cmp.Compare(a.a, b.a), cmp.Compare(a.b, b.b))
+		})
+	}
+	return keys
+}
@@ -0,0 +1,97 @@
+package physics
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/anthdm/hollywood/actor"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// runDeterministic registers a pile of boxes in the given order and runs a
+// deterministic world for a number of ticks of the given wall-clock durations
+func runDeterministic(order []int, deltaTime func() float64, ticks int) *Physics {
+	p := &Physics{deterministic: true}
+	p.init()
+
+	for _, i := range order {
+		p.pending = append(p.pending, EventRigidBodyRegister{
+			PID: actor.NewPID("local", fmt.Sprintf("box_%d", i)),
+			EntityRigidBody: EntityRigidBody{
+				Position:    mgl64.Vec3{float64(i%3) * 0.7, 1 + float64(i)*1.1, float64(i%2) * 0.4},
+				Scale:       mgl64.Vec3{1, 1, 1},
+				Orientation: mgl64.QuatRotate(float64(i)*0.3, mgl64.Vec3{0, 1, 0}),
+			},
+		})
+	}
+	for i := 0; i < ticks; i++ {
+		if i == 10 {
+			p.pending = append(p.pending, EventApplyImpulse{PID: actor.NewPID("local", "box_3"), Impulse: mgl64.Vec3{4, 0, 1}})
+		}
+		p.tick(deltaTime())
+	}
+	return p
+}
+
+func TestDeterministicRunsHashTheSameWhateverTheOrderAndClock(t *testing.T) {
+	const ticks = 300
+	steady := func() float64 { return FixedDeltaTime }
+	random := rand.New(rand.NewSource(1))
+	jittery := func() float64 { return random.Float64() * 3 * FixedDeltaTime }
+
+	a := runDeterministic([]int{0, 1, 2, 3, 4, 5, 6, 7}, steady, ticks)
+	b := runDeterministic([]int{7, 3, 5, 1, 0, 6, 2, 4}, jittery, ticks)
+
+	for _, tick := range []uint64{1, 100, ticks} {
+		hashA, okA := a.TickStateHash(tick)
+		hashB, okB := b.TickStateHash(tick)
+		if !okA || !okB || hashA != hashB {
+			t.Errorf("tick %d: runs should hash the same, got %x (%v) and %x (%v)", tick, hashA, okA, hashB, okB)
+		}
+	}
+	if _, ok := a.TickStateHash(ticks + 1); ok {
+		t.Error("ticks not simulated yet should have no hash")
+	}
+
+	// Any difference in the state shows up in the hash
+	before := a.StateHash()
+	a.bodies[0].Position[0] += 1e-9
+	if a.StateHash() == before {
+		t.Error("moving a body should change the hash")
+	}
+}
+
+func TestPendingRegistrationsOnlySortAmongThemselves(t *testing.T) {
+	p := &Physics{deterministic: true}
+	p.init()
+
+	a, b, c := actor.NewPID("local", "a"), actor.NewPID("local", "b"), actor.NewPID("local", "c")
+	crate := EntityRigidBody{Position: mgl64.Vec3{0, 5, 0}, Scale: mgl64.Vec3{1, 1, 1}}
+	p.pending = append(p.pending,
+		EventRigidBodyRegister{PID: c, EntityRigidBody: crate},
+		EventRigidBodyRegister{PID: a, EntityRigidBody: crate},
+		EventSetVelocity{PID: a, Velocity: mgl64.Vec3{1, 0, 0}},
+		EventRigidBodyRegister{PID: b, EntityRigidBody: crate},
+		EventSetVelocity{PID: b, Velocity: mgl64.Vec3{2, 0, 0}},
+		EventRigidBodyRegister{PID: b, EntityRigidBody: crate},
+	)
+	p.applyPending()
+
+	// The registrations before the first command are sorted, the velocities
+	// apply to the bodies registered before them and are overridden by the
+	// registration coming after
+	var order []string
+	for _, body := range p.bodies {
+		order = append(order, body.pid.ID)
+	}
+	if fmt.Sprint(order) != "[a c b]" {
+		t.Errorf("bodies should be registered in the order [a c b], got %v", order)
+	}
+	if v := p.entities[a].Velocity; v != (mgl64.Vec3{1, 0, 0}) {
+		t.Errorf("a should keep the velocity set after its registration, got %v", v)
+	}
+	if v := p.entities[b].Velocity; v != (mgl64.Vec3{}) {
+		t.Errorf("b should be reset by its second registration, got %v", v)
+	}
+}
@@ -9,15 +9,55 @@ type EventRigidBodyRegister struct {
 	PID             *actor.PID
 	EntityRigidBody EntityRigidBody
 }
+
+// EventRigidBodyUpdate overwrites the velocity of a body, zero velocity components
+// keep their current value. Prefer EventSetVelocity, EventApplyImpulse and
+// EventApplyForce which have explicit semantics.
 type EventRigidBodyUpdate struct {
 	PID             *actor.PID
 	Velocity        mgl64.Vec3
 	AngularVelocity mgl64.Vec3
 }
+
+// EventApplyForce adds a force to a body, accelerating it by Force divided by
+// its mass in world units per second squared. Forces accumulate until the next
+// server tick, are integrated over every step of that tick and then cleared,
+// so a constant force must be sent every tick.
+type EventApplyForce struct {
+	PID   *actor.PID
+	Force mgl64.Vec3
+}
+
+// EventApplyImpulse changes the velocity of a body immediately by
+// Impulse divided by its mass, in world units per second
+type EventApplyImpulse struct {
+	PID     *actor.PID
+	Impulse mgl64.Vec3
+}
+
+// EventSetVelocity replaces the linear and angular velocity of a body,
+// including zero components, so it can be used to stop a body. Velocities are
+// in world units per second and radians per second.
+type EventSetVelocity struct {
+	PID             *actor.PID
+	Velocity        mgl64.Vec3
+	AngularVelocity mgl64.Vec3
+}
+
+// EventRigidBodyTransform is sent to the owner of every moving body after a
+// tick. Step is the simulation step the transform is the result of, and Alpha
+// how far the simulation time left over is into the next step, in the range
+// [0, 1), to interpolate from the transform of the previous step. That is the
+// transform before step Step, even when a tick ran several steps.
 type EventRigidBodyTransform struct {
-	PID      *actor.PID
-	Position mgl64.Vec3
-	Rotation mgl64.Vec3
+	PID         *actor.PID
+	Position    mgl64.Vec3
+	Orientation mgl64.Quat
+	Step        uint64
+	Alpha       float64
+
+	PreviousPosition    mgl64.Vec3
+	PreviousOrientation mgl64.Quat
 }
 type EventPositionUpdate struct {
 	PID      *actor.PID
@@ -28,7 +68,186 @@ type EventRotationUpdate struct {
 	Rotation mgl64.Vec3
 }
 
+// EventGroundState is sent to characters after every tick with the ground they
+// stand on. Ground is nil in the air and on the floor plane.
 type EventGroundState struct {
 	PID        *actor.PID
 	IsOnGround bool
+	Normal     mgl64.Vec3 // Surface normal of the ground, zero in the air
+	Ground     *actor.PID
+}
+
+// EventCharacterMove sets the walking velocity of a character in world units
+// per second, of which only the horizontal components are used. The velocity
+// is kept until the next EventCharacterMove. Jump asks for a jump, which is
+// remembered for the jump buffer time when the character is in the air.
+type EventCharacterMove struct {
+	PID      *actor.PID
+	Velocity mgl64.Vec3
+	Jump     bool
+}
+
+// EventVehicleInput sets the controls of a vehicle, kept until the next
+// EventVehicleInput. Throttle goes from -1 for full reverse to 1 for full
+// throttle, Steering from -1 for full right to 1 for full left and Brake from
+// 0 to 1.
+type EventVehicleInput struct {
+	PID      *actor.PID
+	Throttle float64
+	Steering float64
+	Brake    float64
+}
+
+// EventVehicleState is sent to vehicles after every tick with their forward
+// speed and the state of their wheels, in the order of their controller
+type EventVehicleState struct {
+	PID    *actor.PID
+	Speed  float64
+	Wheels []WheelState
+}
+
+// Collision describes a contact between the receiving body and Other
+type Collision struct {
+	Other   *actor.PID
+	Point   mgl64.Vec3 // Center of the overlap between both bodies
+	Normal  mgl64.Vec3 // Points from the receiving body towards Other
+	Impulse float64    // Normal impulse exchanged during the last tick
+}
+
+// EventCollisionEnter is sent to both bodies of a pair on the first tick they touch
+type EventCollisionEnter struct {
+	PID *actor.PID
+	Collision
+}
+
+// EventCollisionStay is sent to both bodies of a pair on every following tick
+// they keep touching
+type EventCollisionStay struct {
+	PID *actor.PID
+	Collision
+}
+
+// EventCollisionExit is sent to both bodies of a pair on the first tick they no
+// longer touch, with the last known contact
+type EventCollisionExit struct {
+	PID *actor.PID
+	Collision
+}
+
+// EventTriggerEnter is sent to the owner of a sensor and to the body that
+// started overlapping it
+type EventTriggerEnter struct {
+	PID     *actor.PID
+	Trigger *actor.PID // Owner of the sensor
+	Other   *actor.PID // Body overlapping the sensor
+}
+
+// EventTriggerExit is sent to the owner of a sensor and to the body that
+// stopped overlapping it
+type EventTriggerExit struct {
+	PID     *actor.PID
+	Trigger *actor.PID // Owner of the sensor
+	Other   *actor.PID // Body overlapping the sensor
+}
+
+// EventSetGravity changes the world gravity, every body falls along it scaled
+// by its GravityScale
+type EventSetGravity struct {
+	Gravity mgl64.Vec3
+}
+
+// EventJointCreate adds a joint between two registered bodies, replacing any
+// joint with the same ID
+type EventJointCreate struct {
+	ID string
+	Joint
+}
+
+// EventJointDestroy removes the joint with the given ID
+type EventJointDestroy struct {
+	ID string
+}
+
+// EventJointBroken is sent to the owners of both bodies of a joint that broke
+// because it had to hold more than its break force
+type EventJointBroken struct {
+	PID   *actor.PID
+	ID    string
+	Other *actor.PID // Owner of the other body of the joint
+	Force float64    // Force the joint had to hold when it broke
+}
+
+// EventModelBounds provides the extents of a loaded model so that entities
+// using it collide with the right size
+type EventModelBounds struct {
+	ModelName string
+	Bounds    mgl64.Vec3
+}
+
+// RequestRaycast asks for the bodies hit by a ray, answered with a RaycastResponse.
+// MaxDistance defaults to 1000 units when zero and a LayerMask of zero matches
+// every layer. Only the closest hit is returned unless All is set. Ignore can
+// hold the PID of the caller so that a probe does not hit its own body.
+type RequestRaycast struct {
+	Origin      mgl64.Vec3
+	Direction   mgl64.Vec3
+	MaxDistance float64
+	LayerMask   uint32
+	All         bool
+	Ignore      *actor.PID
+}
+
+// RaycastResponse holds the hits of a RequestRaycast ordered by distance
+type RaycastResponse struct {
+	Hits []QueryHit
+}
+
+// RequestShapeCast sweeps a box with the given half extents from Origin along
+// Direction, answered with a ShapeCastResponse. The other fields behave as in
+// RequestRaycast.
+type RequestShapeCast struct {
+	Origin      mgl64.Vec3
+	Direction   mgl64.Vec3
+	HalfExtents mgl64.Vec3
+	MaxDistance float64
+	LayerMask   uint32
+	All         bool
+	Ignore      *actor.PID
+}
+
+// ShapeCastResponse holds the hits of a RequestShapeCast ordered by distance
+type ShapeCastResponse struct {
+	Hits []QueryHit
+}
+
+// RequestStateHash asks for the hash of the state of every body after a tick,
+// answered with a StateHashResponse. Hashes are only kept by the deterministic
+// physics system, for the last 1024 ticks.
+type RequestStateHash struct {
+	Tick uint64
+}
+
+// StateHashResponse holds the hash of a RequestStateHash. Found is false when
+// the tick is not among the recorded ones.
+type StateHashResponse struct {
+	Tick  uint64
+	Hash  uint64
+	Found bool
+}
+
+// EventSetDebugDraw selects the debug geometry built after every tick, zero
+// turns it off
+type EventSetDebugDraw struct {
+	Draw DebugDraw
+}
+
+// RequestDebugGeometry asks for the debug geometry of the latest tick,
+// answered with a DebugGeometryResponse
+type RequestDebugGeometry struct{}
+
+// DebugGeometryResponse holds the debug lines built after a step. The lines
+// are never changed once sent.
+type DebugGeometryResponse struct {
+	Step  uint64
+	Lines []DebugLine
 }
@@ -0,0 +1,110 @@
+package physics
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// DefaultGravity is the world gravity until an EventSetGravity changes it,
+// 9.8 m/s² tuned for the scale of the game world
+var DefaultGravity = mgl64.Vec3{0, -9.8 * 1.4, 0}
+
+// ForceFieldType decides how a force field pushes the bodies inside it
+type ForceFieldType int
+
+const (
+	// ForceFieldWind pushes every body inside with the same force, so light
+	// bodies are blown away faster than heavy ones
+	ForceFieldWind ForceFieldType = iota
+	// ForceFieldRadial accelerates every body inside towards the center of the
+	// field, whatever its mass, like a small planet
+	ForceFieldRadial
+	// ForceFieldBuoyancy is a volume of fluid whose top is the top of the field.
+	// Bodies are pushed up by the weight of the fluid they displace, so bodies
+	// lighter than the fluid float.
+	ForceFieldBuoyancy
+)
+
+// ForceField turns a body into a volume that pushes the dynamic bodies whose
+// center is inside its box. Force field bodies are sensors, so they never
+// collide and report the bodies entering and leaving them through trigger events.
+type ForceField struct {
+	Type     ForceFieldType
+	Force    mgl64.Vec3 // Force pushing bodies inside a wind field
+	Strength float64    // Acceleration towards the center of a radial field, negative pushes away
+	Density  float64    // Mass per unit volume of the fluid of a buoyancy field
+	Drag     float64    // Per second damping of the velocity of bodies inside, scaled by how deep they are in fluids
+}
+
+// SetGravity changes the world gravity. Sleeping bodies are woken up, as what
+// held them at rest may no longer do so.
+func (p *Physics) SetGravity(gravity mgl64.Vec3) {
+	p.gravity = gravity
+	for _, b := range p.bodies {
+		p.wake(b)
+	}
+}
+
+// setForceField makes a body a force field, which is always a sensor
+func (b *body) setForceField(field *ForceField) {
+	if field != nil {
+		b.Sensor = true
+	}
+}
+
+// wakeInside wakes the sleeping bodies a force field was placed over, bodies
+// already awake are pushed by it on the next step
+func (p *Physics) wakeInside(field *body) {
+	p.staticBroadphase.Query(field.bounds(), func(id int32) {
+		if b := p.staticOwners[id]; b != nil && b.sleeping {
+			p.touchedSleeping = append(p.touchedSleeping, b)
+		}
+	})
+	p.wakeTouched()
+}
+
+// applyForceFields changes the velocity of the moving dynamic bodies inside
+// every force field. Sleeping bodies are left alone, a body resting in a field
+// has already settled against its push.
+func (p *Physics) applyForceFields(deltaTime float64) {
+	for _, field := range p.fields {
+		box := field.box()
+		p.broadphase.Query(field.bounds(), func(id int32) {
+			b := p.owners[id]
+			if b == nil || b.BodyType != BodyDynamic || b.Sensor || !field.interacts(b) || !box.contains(b.Position, 0) {
+				return
+			}
+			p.applyForceField(field, b, deltaTime)
+		})
+	}
+}
+
+// applyForceField changes the velocity of a body inside a force field
+func (p *Physics) applyForceField(field, b *body, deltaTime float64) {
+	settings := field.ForceField
+	depth := 1.0
+
+	switch settings.Type {
+	case ForceFieldWind:
+		b.Velocity = b.Velocity.Add(settings.Force.Mul(b.InverseMass * deltaTime))
+	case ForceFieldRadial:
+		toCenter := field.Position.Sub(b.Position)
+		if distance := toCenter.Len(); distance > 1e-9 {
+			b.Velocity = b.Velocity.Add(toCenter.Mul(settings.Strength * deltaTime / distance))
+		}
+	case ForceFieldBuoyancy:
+		// Fraction of the height of the body below the surface of the fluid
+		extents := b.worldExtents()
+		surface := field.bounds().Max.Y()
+		depth = mgl64.Clamp((surface-(b.Position.Y()-extents.Y()))/(2*extents.Y()), 0, 1)
+
+		volume := 8 * b.halfExtents.X() * b.halfExtents.Y() * b.halfExtents.Z()
+		buoyancy := p.gravity.Mul(-settings.Density * volume * depth)
+		b.Velocity = b.Velocity.Add(buoyancy.Mul(b.InverseMass * deltaTime))
+	}
+
+	if settings.Drag > 0 {
+		b.Velocity = b.Velocity.Mul(math.Exp(-settings.Drag * depth * deltaTime))
+	}
+}
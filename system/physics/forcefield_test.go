@@ -0,0 +1,121 @@
+package physics
+
+import (
+	"math"
+	"testing"
+
+	"github.com/anthdm/hollywood/actor"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+func TestGravityFollowsWorldSettingAndBodyScale(t *testing.T) {
+	p := newTestPhysics()
+
+	none, reversed := 0.0, -0.5
+	resting := actor.NewPID("local", "resting")
+	floating := actor.NewPID("local", "floating")
+	rising := actor.NewPID("local", "rising")
+	p.Register(resting, EntityRigidBody{Position: mgl64.Vec3{0, 0.5, 0}, Scale: mgl64.Vec3{1, 1, 1}})
+	p.Register(floating, EntityRigidBody{Position: mgl64.Vec3{5, 5, 0}, Scale: mgl64.Vec3{1, 1, 1}, GravityScale: &none})
+	p.Register(rising, EntityRigidBody{Position: mgl64.Vec3{-5, 5, 0}, Scale: mgl64.Vec3{1, 1, 1}, GravityScale: &reversed})
+
+	for i := 0; i < 256; i++ {
+		p.Step(FixedDeltaTime)
+	}
+	if y := p.entities[floating].Position.Y(); y != 5 {
+		t.Errorf("body without gravity should float in place, got y=%f", y)
+	}
+	if y := p.entities[rising].Position.Y(); y < 7 {
+		t.Errorf("body with a negative gravity scale should rise, got y=%f", y)
+	}
+	if !p.entities[resting].sleeping {
+		t.Fatal("body on the floor should have fallen asleep")
+	}
+
+	// Gravity pointing sideways wakes the resting body and pulls it along +X
+	p.SetGravity(mgl64.Vec3{20, 0, 0})
+	for i := 0; i < 64; i++ {
+		p.Step(FixedDeltaTime)
+	}
+	if x := p.entities[resting].Position.X(); x <= 0.1 {
+		t.Errorf("body should fall along the new gravity, got x=%f", x)
+	}
+	if x := p.entities[floating].Position.X(); x != 5 {
+		t.Errorf("body without gravity should ignore the new gravity, got x=%f", x)
+	}
+}
+
+func TestForceFieldsPushBodiesInside(t *testing.T) {
+	p := newTestPhysics()
+
+	wind := actor.NewPID("local", "wind")
+	well := actor.NewPID("local", "well")
+	p.Register(wind, EntityRigidBody{
+		Position:   mgl64.Vec3{0, 2, 0},
+		Scale:      mgl64.Vec3{4, 4, 4},
+		BodyType:   BodyStatic,
+		ForceField: &ForceField{Type: ForceFieldWind, Force: mgl64.Vec3{0, 0, 40}},
+	})
+	p.Register(well, EntityRigidBody{
+		Position:   mgl64.Vec3{20, 10, 0},
+		Scale:      mgl64.Vec3{10, 10, 10},
+		BodyType:   BodyStatic,
+		ForceField: &ForceField{Type: ForceFieldRadial, Strength: 30, Drag: 2},
+	})
+
+	light := actor.NewPID("local", "light")
+	heavy := actor.NewPID("local", "heavy")
+	orbiting := actor.NewPID("local", "orbiting")
+	p.Register(light, EntityRigidBody{Position: mgl64.Vec3{-1, 0.5, 0}, Scale: mgl64.Vec3{0.5, 0.5, 0.5}, Material: &IceMaterial})
+	p.Register(heavy, EntityRigidBody{Position: mgl64.Vec3{1, 0.5, 0}, Scale: mgl64.Vec3{0.5, 0.5, 0.5}, Material: &IceMaterial, Mass: 10})
+	p.Register(orbiting, EntityRigidBody{Position: mgl64.Vec3{23, 12, 0}, Scale: mgl64.Vec3{0.5, 0.5, 0.5}})
+
+	for i := 0; i < 64; i++ {
+		p.Step(FixedDeltaTime)
+	}
+
+	// Fields never collide with what they push
+	if !p.entities[wind].Sensor || p.entities[light].Position.Y() < 0.2 {
+		t.Error("force fields should be sensors")
+	}
+	lightZ, heavyZ := p.entities[light].Position.Z(), p.entities[heavy].Position.Z()
+	if lightZ <= 0 || heavyZ <= 0 || lightZ < 5*heavyZ {
+		t.Errorf("wind should blow light bodies further than heavy ones, got z=%f and z=%f", lightZ, heavyZ)
+	}
+
+	for i := 0; i < 256*2; i++ {
+		p.Step(FixedDeltaTime)
+	}
+	if distance := p.entities[orbiting].Position.Sub(mgl64.Vec3{20, 10, 0}).Len(); distance > 1 {
+		t.Errorf("gravity well should pull the body to its center, %f away", distance)
+	}
+}
+
+func TestBuoyancyFloatsLightBodiesAndSinksHeavyOnes(t *testing.T) {
+	p := newTestPhysics()
+
+	pool := actor.NewPID("local", "pool")
+	p.Register(pool, EntityRigidBody{
+		Position:   mgl64.Vec3{0, 2, 0},
+		Scale:      mgl64.Vec3{10, 4, 10},
+		BodyType:   BodyStatic,
+		ForceField: &ForceField{Type: ForceFieldBuoyancy, Density: 2, Drag: 3},
+	})
+
+	cork := actor.NewPID("local", "cork")
+	stone := actor.NewPID("local", "stone")
+	p.Register(cork, EntityRigidBody{Position: mgl64.Vec3{-2, 6, 0}, Scale: mgl64.Vec3{1, 1, 1}})
+	p.Register(stone, EntityRigidBody{Position: mgl64.Vec3{2, 6, 0}, Scale: mgl64.Vec3{1, 1, 1}, Mass: 3})
+
+	for i := 0; i < 256*4; i++ {
+		p.Step(FixedDeltaTime)
+	}
+
+	// Half as dense as the water, the cork floats half submerged
+	if y := p.entities[cork].Position.Y(); math.Abs(y-4) > 0.05 {
+		t.Errorf("cork should float with its center at the surface, got y=%f", y)
+	}
+	if y := p.entities[stone].Position.Y(); math.Abs(y-0.5) > 0.05 {
+		t.Errorf("stone should sink to the bottom, got y=%f", y)
+	}
+}
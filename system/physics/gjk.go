@@ -0,0 +1,417 @@
+package physics
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+const (
+	// gjkIterations and epaIterations bound the refinement of both algorithms,
+	// rounded shapes would otherwise be refined forever
+	gjkIterations = 32
+	epaIterations = 64
+
+	// epaTolerance is how close to the surface of the Minkowski difference EPA
+	// has to get before its depth is accepted
+	epaTolerance = 1e-4
+)
+
+// supportPoint is a vertex of the Minkowski difference of two shapes together
+// with the points of both shapes it was made of
+type supportPoint struct {
+	point, a, b mgl64.Vec3
+}
+
+// simplex is the set of up to four vertices GJK keeps around the point of the
+// Minkowski difference closest to the origin, with their barycentric weights
+type simplex struct {
+	vertices [4]supportPoint
+	weights  [4]float64
+	count    int
+}
+
+// gjkResult is what GJK learns about two shapes: the distance and direction
+// between their cores, or that the cores overlap
+type gjkResult struct {
+	distance float64
+	normal   mgl64.Vec3 // Points from a towards b
+	overlap  bool
+	simplex  simplex
+}
+
+// coreDifference returns the vertex of the Minkowski difference of the cores
+// of two shapes furthest along a direction
+func coreDifference(a, b *convex, direction mgl64.Vec3) supportPoint {
+	pointA := a.coreSupport(direction)
+	pointB := b.coreSupport(direction.Mul(-1))
+	return supportPoint{point: pointA.Sub(pointB), a: pointA, b: pointB}
+}
+
+// gjk finds the distance between the cores of two convex shapes by walking a
+// simplex of their Minkowski difference towards the origin
+func gjk(a, b *convex) gjkResult {
+	var s simplex
+	v := a.position.Sub(b.position)
+	if v.LenSqr() < 1e-18 {
+		v = mgl64.Vec3{1, 0, 0}
+	}
+
+	for i := 0; i < gjkIterations; i++ {
+		w := coreDifference(a, b, v.Mul(-1))
+
+		// No vertex is closer to the origin than the simplex already is
+		if s.count > 0 && v.LenSqr()-v.Dot(w.point) <= 1e-10*v.LenSqr() {
+			break
+		}
+		if s.contains(w.point) {
+			break
+		}
+
+		s.vertices[s.count] = w
+		s.count++
+		v = s.closest()
+
+		if s.count == 4 || v.LenSqr() < 1e-18 {
+			return gjkResult{overlap: true, simplex: s}
+		}
+	}
+
+	pointA, pointB := s.witnesses()
+	delta := pointB.Sub(pointA)
+	distance := delta.Len()
+	if distance < 1e-9 {
+		return gjkResult{overlap: true, simplex: s}
+	}
+	return gjkResult{distance: distance, normal: delta.Mul(1 / distance), simplex: s}
+}
+
+// witnesses returns the closest points of the cores of both shapes, weighted
+// like the vertices of the simplex they were made of
+func (s *simplex) witnesses() (mgl64.Vec3, mgl64.Vec3) {
+	var pointA, pointB mgl64.Vec3
+	for i := 0; i < s.count; i++ {
+		pointA = pointA.Add(s.vertices[i].a.Mul(s.weights[i]))
+		pointB = pointB.Add(s.vertices[i].b.Mul(s.weights[i]))
+	}
+	return pointA, pointB
+}
+
+// contains reports whether a point is already a vertex of the simplex
+func (s *simplex) contains(point mgl64.Vec3) bool {
+	for i := 0; i < s.count; i++ {
+		if s.vertices[i].point.Sub(point).LenSqr() < 1e-18 {
+			return true
+		}
+	}
+	return false
+}
+
+// closest returns the point of the simplex closest to the origin, dropping the
+// vertices that do not contribute to it and recording the weights of the rest.
+// A tetrahedron containing the origin is kept whole.
+func (s *simplex) closest() mgl64.Vec3 {
+	switch s.count {
+	case 1:
+		s.weights[0] = 1
+		return s.vertices[0].point
+	case 2:
+		return s.closestSegment(0, 1)
+	case 3:
+		return s.closestTriangle(0, 1, 2)
+	}
+	return s.closestTetrahedron()
+}
+
+// keep reduces the simplex to the given vertices with their weights
+func (s *simplex) keep(indices []int, weights []float64) {
+	var vertices [4]supportPoint
+	for i, index := range indices {
+		vertices[i] = s.vertices[index]
+	}
+	s.vertices = vertices
+	s.count = len(indices)
+	copy(s.weights[:], weights)
+}
+
+// closestSegment reduces the simplex to the part of the segment between two
+// of its vertices closest to the origin
+func (s *simplex) closestSegment(i, j int) mgl64.Vec3 {
+	a, b := s.vertices[i].point, s.vertices[j].point
+	ab := b.Sub(a)
+	t := -a.Dot(ab) / ab.LenSqr()
+	switch {
+	case t <= 0 || math.IsNaN(t):
+		s.keep([]int{i}, []float64{1})
+		return a
+	case t >= 1:
+		s.keep([]int{j}, []float64{1})
+		return b
+	}
+	s.keep([]int{i, j}, []float64{1 - t, t})
+	return a.Add(ab.Mul(t))
+}
+
+// closestTriangle reduces the simplex to the part of the triangle between
+// three of its vertices closest to the origin, by finding the Voronoi region
+// of the triangle the origin is in
+func (s *simplex) closestTriangle(i, j, k int) mgl64.Vec3 {
+	a, b, c := s.vertices[i].point, s.vertices[j].point, s.vertices[k].point
+	ab, ac := b.Sub(a), c.Sub(a)
+
+	d1, d2 := -ab.Dot(a), -ac.Dot(a)
+	if d1 <= 0 && d2 <= 0 {
+		s.keep([]int{i}, []float64{1})
+		return a
+	}
+
+	d3, d4 := -ab.Dot(b), -ac.Dot(b)
+	if d3 >= 0 && d4 <= d3 {
+		s.keep([]int{j}, []float64{1})
+		return b
+	}
+
+	if vc := d1*d4 - d3*d2; vc <= 0 && d1 >= 0 && d3 <= 0 {
+		t := d1 / (d1 - d3)
+		s.keep([]int{i, j}, []float64{1 - t, t})
+		return a.Add(ab.Mul(t))
+	}
+
+	d5, d6 := -ab.Dot(c), -ac.Dot(c)
+	if d6 >= 0 && d5 <= d6 {
+		s.keep([]int{k}, []float64{1})
+		return c
+	}
+
+	if vb := d5*d2 - d1*d6; vb <= 0 && d2 >= 0 && d6 <= 0 {
+		t := d2 / (d2 - d6)
+		s.keep([]int{i, k}, []float64{1 - t, t})
+		return a.Add(ac.Mul(t))
+	}
+
+	if va := d3*d6 - d5*d4; va <= 0 && d4-d3 >= 0 && d5-d6 >= 0 {
+		t := (d4 - d3) / ((d4 - d3) + (d5 - d6))
+		s.keep([]int{j, k}, []float64{1 - t, t})
+		return b.Add(c.Sub(b).Mul(t))
+	}
+
+	va, vb, vc := d3*d6-d5*d4, d5*d2-d1*d6, d1*d4-d3*d2
+	sum := va + vb + vc
+	if sum == 0 {
+		// Degenerate triangle, its longest edge holds the closest point
+		return s.closestSegment(i, j)
+	}
+	v, w := vb/sum, vc/sum
+	s.keep([]int{i, j, k}, []float64{1 - v - w, v, w})
+	return a.Add(ab.Mul(v)).Add(ac.Mul(w))
+}
+
+// closestTetrahedron keeps the tetrahedron when it contains the origin, and
+// otherwise reduces it to the closest of the faces the origin is in front of
+func (s *simplex) closestTetrahedron() mgl64.Vec3 {
+	original := *s
+	inside := true
+	bestDistance := math.Inf(1)
+	var best simplex
+	var bestPoint mgl64.Vec3
+
+	// Each face with the vertex opposite to it
+	for _, face := range [4][4]int{{0, 1, 2, 3}, {0, 3, 1, 2}, {0, 2, 3, 1}, {1, 3, 2, 0}} {
+		a := original.vertices[face[0]].point
+		normal := original.vertices[face[1]].point.Sub(a).Cross(original.vertices[face[2]].point.Sub(a))
+		origin := -a.Dot(normal)
+		opposite := original.vertices[face[3]].point.Sub(a).Dot(normal)
+
+		// A flat tetrahedron contains nothing, so all of its faces are candidates
+		if origin*opposite >= 0 && math.Abs(opposite) > 1e-12 {
+			continue
+		}
+		inside = false
+
+		candidate := original
+		point := candidate.closestTriangle(face[0], face[1], face[2])
+		if distance := point.LenSqr(); distance < bestDistance {
+			best, bestPoint, bestDistance = candidate, point, distance
+		}
+	}
+
+	if inside {
+		return mgl64.Vec3{}
+	}
+	*s = best
+	return bestPoint
+}
+
+// polytopeFace is a triangle of the polytope EPA grows inside the Minkowski
+// difference, with its outward normal and distance from the origin
+type polytopeFace struct {
+	vertices [3]int
+	normal   mgl64.Vec3
+	distance float64
+}
+
+// epa finds the direction and depth of the overlap of the cores of two shapes.
+// Starting from the simplex GJK ended with, it grows a polytope inside the
+// Minkowski difference of the cores towards the face closest to the origin,
+// whose normal and distance are the shortest way out of the overlap. Cores are
+// polytopes, so this ends, and their radii only add to the depth.
+func epa(a, b *convex, start simplex) (mgl64.Vec3, float64) {
+	vertices := make([]supportPoint, 0, 16)
+	for i := 0; i < start.count; i++ {
+		vertices = append(vertices, start.vertices[i])
+	}
+	vertices = expandSimplex(a, b, vertices)
+	if len(vertices) < 4 {
+		return flatNormal(a, b, vertices), 0
+	}
+
+	// The center of the first tetrahedron stays inside the polytope as it grows,
+	// which tells which way the faces point
+	var center mgl64.Vec3
+	for _, vertex := range vertices {
+		center = center.Add(vertex.point)
+	}
+	center = center.Mul(0.25)
+
+	faces := make([]polytopeFace, 0, 32)
+	addFace := func(i, j, k int) {
+		p := vertices[i].point
+		normal := vertices[j].point.Sub(p).Cross(vertices[k].point.Sub(p))
+		length := normal.Len()
+		if length < 1e-12 {
+			return
+		}
+		normal = normal.Mul(1 / length)
+		if normal.Dot(p.Sub(center)) < 0 {
+			normal = normal.Mul(-1)
+			j, k = k, j
+		}
+		faces = append(faces, polytopeFace{vertices: [3]int{i, j, k}, normal: normal, distance: normal.Dot(p)})
+	}
+	addFace(0, 1, 2)
+	addFace(0, 3, 1)
+	addFace(0, 2, 3)
+	addFace(1, 3, 2)
+
+	var edges [][2]int
+	for iteration := 0; ; iteration++ {
+		if len(faces) == 0 {
+			return flatNormal(a, b, vertices[:3]), 0
+		}
+		closest := 0
+		for i := range faces {
+			if faces[i].distance < faces[closest].distance {
+				closest = i
+			}
+		}
+		face := faces[closest]
+
+		w := coreDifference(a, b, face.normal)
+		if w.point.Dot(face.normal)-face.distance < epaTolerance || iteration == epaIterations {
+			return face.normal, math.Max(face.distance, 0)
+		}
+
+		// Remove the faces the new vertex sees, remembering the edges of the
+		// hole they leave that are only used once
+		vertices = append(vertices, w)
+		index := len(vertices) - 1
+		edges = edges[:0]
+		kept := faces[:0]
+		for _, f := range faces {
+			if f.normal.Dot(w.point.Sub(vertices[f.vertices[0]].point)) <= 0 {
+				kept = append(kept, f)
+				continue
+			}
+			for e := 0; e < 3; e++ {
+				edge := [2]int{f.vertices[e], f.vertices[(e+1)%3]}
+				shared := false
+				for i, other := range edges {
+					if other[0] == edge[1] && other[1] == edge[0] {
+						edges = append(edges[:i], edges[i+1:]...)
+						shared = true
+						break
+					}
+				}
+				if !shared {
+					edges = append(edges, edge)
+				}
+			}
+		}
+		faces = kept
+		for _, edge := range edges {
+			addFace(edge[0], edge[1], index)
+		}
+	}
+}
+
+// expandSimplex grows the simplex GJK stopped with into a tetrahedron, as EPA
+// needs a volume to start from. The origin touching a vertex, an edge or a
+// face of the simplex stops GJK early. Fewer vertices are returned when the
+// Minkowski difference of the cores is flat, such as for two spheres.
+func expandSimplex(a, b *convex, vertices []supportPoint) []supportPoint {
+	axes := [6]mgl64.Vec3{{1, 0, 0}, {-1, 0, 0}, {0, 1, 0}, {0, -1, 0}, {0, 0, 1}, {0, 0, -1}}
+	if len(vertices) == 0 {
+		vertices = append(vertices, coreDifference(a, b, axes[0]))
+	}
+
+	if len(vertices) == 1 {
+		for _, axis := range axes {
+			if w := coreDifference(a, b, axis); w.point.Sub(vertices[0].point).LenSqr() > 1e-12 {
+				vertices = append(vertices, w)
+				break
+			}
+		}
+	}
+
+	if len(vertices) == 2 {
+		line := vertices[1].point.Sub(vertices[0].point)
+		tangents := tangentBasis(line.Normalize())
+		for _, direction := range [4]mgl64.Vec3{tangents[0], tangents[1], tangents[0].Mul(-1), tangents[1].Mul(-1)} {
+			w := coreDifference(a, b, direction)
+			if line.Cross(w.point.Sub(vertices[0].point)).LenSqr() > 1e-12 {
+				vertices = append(vertices, w)
+				break
+			}
+		}
+	}
+
+	if len(vertices) == 3 {
+		p := vertices[0].point
+		normal := vertices[1].point.Sub(p).Cross(vertices[2].point.Sub(p))
+		for _, direction := range [2]mgl64.Vec3{normal, normal.Mul(-1)} {
+			w := coreDifference(a, b, direction)
+			if math.Abs(w.point.Sub(p).Dot(normal)) > 1e-12 {
+				vertices = append(vertices, w)
+				break
+			}
+		}
+	}
+
+	return vertices
+}
+
+// flatNormal returns the direction out of a flat Minkowski difference of two
+// cores, spanned by up to three vertices: perpendicular to it and as close as
+// possible to the direction from a towards b
+func flatNormal(a, b *convex, vertices []supportPoint) mgl64.Vec3 {
+	normal := b.position.Sub(a.position)
+	switch len(vertices) {
+	case 2:
+		line := vertices[1].point.Sub(vertices[0].point).Normalize()
+		normal = normal.Sub(line.Mul(normal.Dot(line)))
+		if normal.LenSqr() < 1e-18 {
+			normal = tangentBasis(line)[0]
+		}
+	case 3:
+		p := vertices[0].point
+		plane := vertices[1].point.Sub(p).Cross(vertices[2].point.Sub(p)).Normalize()
+		if plane.Dot(normal) < 0 {
+			plane = plane.Mul(-1)
+		}
+		normal = plane
+	}
+	if normal.LenSqr() < 1e-18 {
+		return mgl64.Vec3{0, 1, 0}
+	}
+	return normal.Normalize()
+}
@@ -0,0 +1,316 @@
+package physics
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/jpeg" // Heightmaps may be JPEG images
+	_ "image/png"  // or PNG images
+	"math"
+	"os"
+
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// terrainBisections is how many times a ray crossing the terrain between two
+// samples is halved to find where it hits the surface
+const terrainBisections = 24
+
+// Heightfield is a grid of terrain heights in world units, centered on the
+// position of the body using it. Every cell of the grid is split into two
+// triangles along the diagonal going from its lowest X and Z corner. Beyond
+// its borders the terrain keeps the height of the nearest border.
+type Heightfield struct {
+	Columns int       // Samples along X
+	Rows    int       // Samples along Z
+	Spacing float64   // Distance between neighbouring samples
+	Heights []float64 // Columns samples per row, rows ordered along Z
+}
+
+// NewHeightfield samples a function of the local X and Z coordinates, centered
+// on the terrain, into a heightfield
+func NewHeightfield(columns, rows int, spacing float64, height func(x, z float64) float64) *Heightfield {
+	h := &Heightfield{Columns: columns, Rows: rows, Spacing: spacing, Heights: make([]float64, columns*rows)}
+	for row := 0; row < rows; row++ {
+		for column := 0; column < columns; column++ {
+			point := h.point(column, row)
+			h.Heights[row*columns+column] = height(point.X(), point.Z())
+		}
+	}
+	return h
+}
+
+// HeightfieldFromImage turns a grayscale image into a heightfield with one
+// sample per pixel, black being at zero and white at maxHeight
+func HeightfieldFromImage(img image.Image, spacing, maxHeight float64) *Heightfield {
+	bounds := img.Bounds()
+	h := &Heightfield{
+		Columns: bounds.Dx(),
+		Rows:    bounds.Dy(),
+		Spacing: spacing,
+		Heights: make([]float64, bounds.Dx()*bounds.Dy()),
+	}
+	for row := 0; row < h.Rows; row++ {
+		for column := 0; column < h.Columns; column++ {
+			gray := color.Gray16Model.Convert(img.At(bounds.Min.X+column, bounds.Min.Y+row)).(color.Gray16)
+			h.Heights[row*h.Columns+column] = float64(gray.Y) / math.MaxUint16 * maxHeight
+		}
+	}
+	return h
+}
+
+// LoadHeightfield reads a PNG or JPEG grayscale image into a heightfield
+func LoadHeightfield(path string, spacing, maxHeight float64) (*Heightfield, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open heightmap %s: %w", path, err)
+	}
+	defer file.Close()
+
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode heightmap %s: %w", path, err)
+	}
+	return HeightfieldFromImage(img, spacing, maxHeight), nil
+}
+
+// HeightfieldShape returns a terrain collider. A static body using it becomes
+// the ground of the world in place of the floor plane at y=0, ignoring its
+// scale and orientation. Without samples the body keeps its collision box.
+func HeightfieldShape(heightfield *Heightfield) *Shape {
+	return &Shape{Type: ShapeHeightfield, Heightfield: heightfield}
+}
+
+// point returns the local position of a sample, without its height
+func (h *Heightfield) point(column, row int) mgl64.Vec3 {
+	return mgl64.Vec3{
+		(float64(column) - float64(h.Columns-1)/2) * h.Spacing,
+		0,
+		(float64(row) - float64(h.Rows-1)/2) * h.Spacing,
+	}
+}
+
+// height returns the height of a sample, clamped to the grid
+func (h *Heightfield) height(column, row int) float64 {
+	column = max(0, min(column, h.Columns-1))
+	row = max(0, min(row, h.Rows-1))
+	return h.Heights[row*h.Columns+column]
+}
+
+// empty reports whether a heightfield is missing or has no samples to use
+func (h *Heightfield) empty() bool {
+	return h == nil || h.Columns <= 0 || h.Rows <= 0 || len(h.Heights) < h.Columns*h.Rows
+}
+
+// Sample returns the height and the surface normal of the terrain at local X
+// and Z coordinates, following the triangles of the cell below them
+func (h *Heightfield) Sample(x, z float64) (float64, mgl64.Vec3) {
+	if h.empty() {
+		return 0, mgl64.Vec3{0, 1, 0}
+	}
+
+	// Past the borders the nearest border is stretched out flat
+	fx := x/h.Spacing + float64(h.Columns-1)/2
+	fz := z/h.Spacing + float64(h.Rows-1)/2
+	flatX, flatZ := fx < 0 || fx > float64(h.Columns-1), fz < 0 || fz > float64(h.Rows-1)
+	fx = math.Max(0, math.Min(fx, float64(h.Columns-1)))
+	fz = math.Max(0, math.Min(fz, float64(h.Rows-1)))
+
+	column, row := int(math.Min(fx, float64(h.Columns-2))), int(math.Min(fz, float64(h.Rows-2)))
+	column, row = max(column, 0), max(row, 0)
+	u, v := fx-float64(column), fz-float64(row)
+
+	h00, h10 := h.height(column, row), h.height(column+1, row)
+	h01, h11 := h.height(column, row+1), h.height(column+1, row+1)
+
+	var height, slopeX, slopeZ float64
+	if u+v <= 1 {
+		slopeX, slopeZ = h10-h00, h01-h00
+		height = h00 + slopeX*u + slopeZ*v
+	} else {
+		slopeX, slopeZ = h11-h01, h11-h10
+		height = h11 - slopeX*(1-u) - slopeZ*(1-v)
+	}
+	if flatX {
+		slopeX = 0
+	}
+	if flatZ {
+		slopeZ = 0
+	}
+	return height, mgl64.Vec3{-slopeX / h.Spacing, 1, -slopeZ / h.Spacing}.Normalize()
+}
+
+// Triangles returns the surface of the heightfield in local space, facing up,
+// for drawing the terrain
+func (h *Heightfield) Triangles() [][3]mgl64.Vec3 {
+	if h.Columns < 2 || h.Rows < 2 {
+		return nil
+	}
+
+	triangles := make([][3]mgl64.Vec3, 0, 2*(h.Columns-1)*(h.Rows-1))
+	for row := 0; row < h.Rows-1; row++ {
+		for column := 0; column < h.Columns-1; column++ {
+			triangles = append(triangles, h.cellTriangles(column, row)...)
+		}
+	}
+	return triangles
+}
+
+// cellTriangles returns the two triangles of a cell in local space, facing up
+func (h *Heightfield) cellTriangles(column, row int) [][3]mgl64.Vec3 {
+	vertex := func(column, row int) mgl64.Vec3 {
+		point := h.point(column, row)
+		point[1] = h.height(column, row)
+		return point
+	}
+	p00, p10 := vertex(column, row), vertex(column+1, row)
+	p01, p11 := vertex(column, row+1), vertex(column+1, row+1)
+	return [][3]mgl64.Vec3{{p00, p01, p10}, {p11, p10, p01}}
+}
+
+// extents returns the half extents of the box around the heightfield
+func (h *Heightfield) extents() mgl64.Vec3 {
+	var highest float64
+	for _, height := range h.Heights {
+		highest = math.Max(highest, math.Abs(height))
+	}
+	return mgl64.Vec3{
+		float64(h.Columns-1) / 2 * h.Spacing,
+		highest,
+		float64(h.Rows-1) / 2 * h.Spacing,
+	}
+}
+
+// highest returns the highest sample within local X and Z bounds
+func (h *Heightfield) highest(min, max mgl64.Vec3) float64 {
+	first, last := h.cell(min), h.cell(max)
+	highest := math.Inf(-1)
+	for row := first[1]; row <= last[1]+1; row++ {
+		for column := first[0]; column <= last[0]+1; column++ {
+			highest = math.Max(highest, h.height(column, row))
+		}
+	}
+	return highest
+}
+
+// cell returns the column and row of the cell below a local point, clamped to the grid
+func (h *Heightfield) cell(point mgl64.Vec3) [2]int {
+	column := int(math.Floor(point.X()/h.Spacing + float64(h.Columns-1)/2))
+	row := int(math.Floor(point.Z()/h.Spacing + float64(h.Rows-1)/2))
+	return [2]int{max(0, min(column, h.Columns-1)), max(0, min(row, h.Rows-1))}
+}
+
+// groundAt returns the height and surface normal of the ground below a world
+// position: the terrain when the world has one, the floor plane otherwise
+func (b *body) groundAt(point mgl64.Vec3) (float64, mgl64.Vec3) {
+	if b.shape.heightfield == nil {
+		return 0, mgl64.Vec3{0, 1, 0}
+	}
+	local := point.Sub(b.Position)
+	height, normal := b.shape.heightfield.Sample(local.X(), local.Z())
+	return height + b.Position.Y(), normal
+}
+
+// groundTop returns the highest point of the ground within world bounds
+func (b *body) groundTop(bounds AABB) float64 {
+	if b.shape.heightfield == nil {
+		return 0
+	}
+	return b.shape.heightfield.highest(bounds.Min.Sub(b.Position), bounds.Max.Sub(b.Position)) + b.Position.Y()
+}
+
+// terrainTriangles calls visit with the triangles of the terrain of a body
+// under world bounds, in world space. The flat terrain beyond the borders of
+// the grid has no triangles.
+func (b *body) terrainTriangles(bounds AABB, visit func(triangle *convex)) {
+	h := b.shape.heightfield
+	if h.Columns < 2 || h.Rows < 2 {
+		return
+	}
+	first, last := h.cell(bounds.Min.Sub(b.Position)), h.cell(bounds.Max.Sub(b.Position))
+	for row := first[1]; row <= min(last[1], h.Rows-2); row++ {
+		for column := first[0]; column <= min(last[0], h.Columns-2); column++ {
+			for _, corners := range h.cellTriangles(column, row) {
+				triangle := convex{position: b.Position, rotation: mgl64.Ident3(), points: corners[:]}
+				visit(&triangle)
+			}
+		}
+	}
+}
+
+// terrainSeparation finds how deep a body sinks into the terrain. The terrain
+// below the center of the body is treated as a plane, and the point of the
+// body deepest through it is measured against the height right above it.
+func terrainSeparation(a, ground *body) (mgl64.Vec3, float64, bool) {
+	_, normal := ground.groundAt(a.Position)
+	shape := a.convex()
+	deepest := shape.support(normal.Mul(-1))
+	height, _ := ground.groundAt(deepest)
+
+	depth := (height - deepest.Y()) * normal.Y()
+	if depth <= 0 {
+		return mgl64.Vec3{}, 0, false
+	}
+	return normal.Mul(-1), depth, true
+}
+
+// terrainContacts appends a contact for every corner of the box of a below the
+// terrain of ground. Every corner is measured against the height and slope
+// right under it, so a box lying across a ridge or in a hollow is held where
+// it actually touches.
+func terrainContacts(a, ground *body, contacts []contact) []contact {
+	for i, corner := range a.box().corners() {
+		normal, depth, ok := cornerSeparation(corner, ground)
+		if !ok {
+			continue
+		}
+		c := contact{a: a, b: ground, normal: normal, depth: depth, feature: int32(i)}
+		c.addPoint(corner)
+		contacts = append(contacts, c)
+	}
+	return contacts
+}
+
+// cornerSeparation finds how deep a corner sinks into the terrain below it
+func cornerSeparation(corner mgl64.Vec3, ground *body) (mgl64.Vec3, float64, bool) {
+	height, normal := ground.groundAt(corner)
+	depth := (height - corner.Y()) * normal.Y()
+	if depth <= 0 {
+		return mgl64.Vec3{}, 0, false
+	}
+	return normal.Mul(-1), depth, true
+}
+
+// intersectTerrain returns the distance along a normalized ray to the terrain
+// of a body and the surface normal where it hits. The ray is walked in steps
+// of half the sample spacing and the step crossing the surface is bisected.
+func (b *body) intersectTerrain(origin, direction mgl64.Vec3, maxDistance float64) (float64, mgl64.Vec3, bool) {
+	above := func(distance float64) float64 {
+		point := origin.Add(direction.Mul(distance))
+		height, _ := b.groundAt(point)
+		return point.Y() - height
+	}
+	if above(0) <= 0 {
+		return 0, direction.Mul(-1), true
+	}
+
+	step := b.shape.heightfield.Spacing / 2
+	for near := 0.0; near < maxDistance; near += step {
+		far := math.Min(near+step, maxDistance)
+		if above(far) > 0 {
+			continue
+		}
+
+		for i := 0; i < terrainBisections; i++ {
+			middle := (near + far) / 2
+			if above(middle) > 0 {
+				near = middle
+			} else {
+				far = middle
+			}
+		}
+		_, normal := b.groundAt(origin.Add(direction.Mul(far)))
+		return far, normal, true
+	}
+	return 0, mgl64.Vec3{}, false
+}
@@ -0,0 +1,124 @@
+package physics
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/anthdm/hollywood/actor"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+func TestHeightfieldFromImageSamplesTheTrianglesOfItsCells(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 2, 2))
+	img.SetGray(1, 0, color.Gray{Y: 255})
+	img.SetGray(1, 1, color.Gray{Y: 255})
+	h := HeightfieldFromImage(img, 2, 4)
+
+	// A ramp rising along X from 0 to 4 over two units
+	for _, x := range []float64{-1, -0.5, 0, 0.5, 1} {
+		height, normal := h.Sample(x, 0.3)
+		if expected := 2 + 2*x; math.Abs(height-expected) > 1e-9 {
+			t.Errorf("height at x=%.1f should be %.1f, got %f", x, expected, height)
+		}
+		if normal.Sub(mgl64.Vec3{-2, 1, 0}.Normalize()).Len() > 1e-9 {
+			t.Errorf("normal at x=%.1f should face up the ramp, got %v", x, normal)
+		}
+	}
+
+	// Past the borders the terrain is flat
+	if height, normal := h.Sample(5, 0); height != 4 || normal != (mgl64.Vec3{0, 1, 0}) {
+		t.Errorf("terrain past the border should keep its height, got %f and %v", height, normal)
+	}
+}
+
+func TestBodiesCollideWithTerrainInPlaceOfTheFloorPlane(t *testing.T) {
+	p := newTestPhysics()
+
+	// A gentle slope going down towards -X, two units above the floor plane at x=0
+	terrain := actor.NewPID("local", "terrain")
+	slope := NewHeightfield(41, 41, 0.5, func(x, z float64) float64 { return 0.2 * x })
+	p.Register(terrain, EntityRigidBody{Position: mgl64.Vec3{0, 2, 0}, Shape: HeightfieldShape(slope)})
+
+	crate := actor.NewPID("local", "crate")
+	puck := actor.NewPID("local", "puck")
+	p.Register(crate, EntityRigidBody{Position: mgl64.Vec3{0, 4, 0}, Scale: mgl64.Vec3{1, 1, 1}})
+	p.Register(puck, EntityRigidBody{Position: mgl64.Vec3{0, 4, 4}, Scale: mgl64.Vec3{1, 1, 1}, Shape: SphereShape(0.5), Material: &IceMaterial})
+
+	for i := 0; i < 256*2; i++ {
+		p.Step(FixedDeltaTime)
+	}
+
+	// The crate stays on the slope, lying flat against it
+	b := p.entities[crate]
+	height, normal := p.ground.groundAt(b.Position)
+	if distance := b.Position.Sub(mgl64.Vec3{b.Position.X(), height, b.Position.Z()}).Dot(normal); math.Abs(distance-0.5) > 0.02 {
+		t.Errorf("crate should rest on the slope, got %v, %f from the surface", b.Position, distance)
+	}
+	if b.Position.Y() < 1.5 {
+		t.Errorf("crate should not fall through to the floor plane, got %v", b.Position)
+	}
+
+	// The puck slides down the slope
+	if x := p.entities[puck].Position.X(); x > -1 {
+		t.Errorf("puck should slide down the slope, got x=%f", x)
+	}
+
+	// Rays hit the surface of the terrain
+	hits := p.Raycast(mgl64.Vec3{5, 10, -5}, mgl64.Vec3{0, -1, 0}, 0, 0, false, nil)
+	if len(hits) != 1 || hits[0].PID != terrain || math.Abs(hits[0].Point.Y()-3) > 1e-6 || hits[0].Normal.X() > -0.1 {
+		t.Errorf("ray should hit the slope at y=3, got %+v", hits)
+	}
+
+	// Once the terrain body stops being terrain the floor plane is back. The
+	// crate comes off the slope tilted and may settle on an edge.
+	p.Register(terrain, EntityRigidBody{Position: mgl64.Vec3{0, -50, 0}, Scale: mgl64.Vec3{1, 1, 1}, BodyType: BodyStatic})
+	for i := 0; i < 256*2; i++ {
+		p.Step(FixedDeltaTime)
+	}
+	if y := p.entities[crate].Position.Y(); y > 0.75 {
+		t.Errorf("crate should fall onto the floor plane, got y=%f", y)
+	}
+}
+
+func TestBoxesTouchTerrainAtEachCorner(t *testing.T) {
+	p := newTestPhysics()
+
+	// A ledge under one end of the crate, away from its center
+	terrain := actor.NewPID("local", "terrain")
+	ledge := NewHeightfield(41, 41, 0.25, func(x, z float64) float64 {
+		if x > 0.7 && x < 1.3 {
+			return 0.3
+		}
+		return 0
+	})
+	p.Register(terrain, EntityRigidBody{Shape: HeightfieldShape(ledge)})
+
+	crate := actor.NewPID("local", "crate")
+	p.Register(crate, EntityRigidBody{Position: mgl64.Vec3{0, 0.8, 0}, Scale: mgl64.Vec3{2, 0.5, 1}})
+	for i := 0; i < 256*4; i++ {
+		p.Step(FixedDeltaTime)
+	}
+
+	// The crate leans on the ledge with one end and on the ground with the
+	// other, every corner held above the terrain right under it
+	b := p.entities[crate]
+	if math.Abs(b.Position.X()) > 0.01 || math.Abs(b.Position.Z()) > 0.01 {
+		t.Errorf("crate should not slide off where it landed, got %v", b.Position)
+	}
+	if up := b.rotation.Col(1); up.X() > -0.1 || math.Abs(up.Z()) > 1e-3 {
+		t.Errorf("crate should only lean away from the ledge, its up axis is %v", up)
+	}
+	for _, corner := range b.box().corners() {
+		if height, _ := p.ground.groundAt(corner); height-corner.Y() > 2*penetrationSlop {
+			t.Errorf("corner %v should not sink into the terrain at height %f", corner, height)
+		}
+	}
+
+	// A terrain without samples is no terrain
+	p.Register(terrain, EntityRigidBody{Shape: HeightfieldShape(nil)})
+	if p.terrain != nil || p.entities[terrain].shape.kind != ShapeBox {
+		t.Error("a body with an empty heightfield should keep its collision box")
+	}
+}
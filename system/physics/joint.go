@@ -0,0 +1,288 @@
+package physics
+
+import (
+	"github.com/anthdm/hollywood/actor"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// jointBias is the fraction of the drift of a joint corrected on every step.
+// Correcting all of it at once would make chains and hinges jitter.
+const jointBias = 0.2
+
+// JointType decides which movements between two bodies a joint allows
+type JointType int
+
+const (
+	// JointBallSocket pins both bodies together at the anchor, letting them
+	// rotate freely around it
+	JointBallSocket JointType = iota
+	// JointDistance keeps the anchors of both bodies at a fixed distance, like
+	// a rigid rod between them
+	JointDistance
+	// JointHinge pins both bodies together at the anchor and only lets them
+	// rotate around the hinge axis, like a door
+	JointHinge
+	// JointFixed welds both bodies together
+	JointFixed
+)
+
+// Joint describes a constraint between two registered bodies. Anchors and the
+// axis are given in world space at creation and then move with the bodies.
+type Joint struct {
+	Type       JointType
+	A, B       *actor.PID
+	AnchorA    mgl64.Vec3 // Point where the joint holds A
+	AnchorB    mgl64.Vec3 // Point where the joint holds B, only used by distance joints
+	Axis       mgl64.Vec3 // Rotation axis of hinges, zero uses the Y axis
+	Length     float64    // Length of distance joints, zero keeps the distance at creation
+	BreakForce float64    // Force above which the joint breaks, zero never breaks
+}
+
+// joint is the simulation state of a Joint
+type joint struct {
+	Joint
+
+	id             string
+	a, b           *body
+	localA, localB mgl64.Vec3 // Anchors in the local space of each body
+	axisA, axisB   mgl64.Vec3 // Hinge axis in the local space of each body
+	rest           mgl64.Quat // Orientation of B relative to A that fixed joints keep
+
+	// Per step solver state
+	rA, rB    mgl64.Vec3 // World space offsets of the anchors from the body centers
+	pointMass mgl64.Mat3 // Inverse of the mass felt by an impulse at the anchors
+	axis      mgl64.Vec3 // Distance joint direction from the anchor of A to the anchor of B
+	axisMass  float64
+	bias      mgl64.Vec3 // Velocity correcting the drift of the anchors
+	angular   mgl64.Vec3 // Velocity correcting the drift of the orientation
+	impulse   mgl64.Vec3 // Linear impulse applied during the step
+}
+
+// brokenJoint is a joint that broke during the last tick, waiting to be reported
+type brokenJoint struct {
+	id    string
+	a, b  *body
+	force float64
+}
+
+// CreateJoint adds a joint between two registered bodies, replacing any joint
+// with the same id. Joints between unknown bodies are ignored. Both bodies are
+// woken up and no longer collide with each other.
+func (p *Physics) CreateJoint(id string, spec Joint) {
+	a, okA := p.entities[spec.A]
+	b, okB := p.entities[spec.B]
+	if !okA || !okB || a == b {
+		return
+	}
+	p.DestroyJoint(id)
+
+	if spec.AnchorB == (mgl64.Vec3{}) {
+		spec.AnchorB = spec.AnchorA
+	}
+	if spec.Axis == (mgl64.Vec3{}) {
+		spec.Axis = mgl64.Vec3{0, 1, 0}
+	}
+	spec.Axis = spec.Axis.Normalize()
+	if spec.Type == JointDistance && spec.Length <= 0 {
+		spec.Length = spec.AnchorB.Sub(spec.AnchorA).Len()
+	}
+
+	j := &joint{
+		Joint:  spec,
+		id:     id,
+		a:      a,
+		b:      b,
+		localA: a.toLocal(spec.AnchorA.Sub(a.Position)),
+		localB: b.toLocal(spec.AnchorB.Sub(b.Position)),
+		axisA:  a.toLocal(spec.Axis),
+		axisB:  b.toLocal(spec.Axis),
+		rest:   a.Orientation.Inverse().Mul(b.Orientation),
+	}
+	p.joints = append(p.joints, j)
+	p.jointPairs[makePairKey(a, b)]++
+
+	p.wake(a)
+	p.wake(b)
+}
+
+// DestroyJoint removes a joint, it does nothing for unknown ids
+func (p *Physics) DestroyJoint(id string) {
+	for i, j := range p.joints {
+		if j.id == id {
+			p.removeJoint(i)
+			return
+		}
+	}
+}
+
+// removeJoint removes the joint at index i, preserving the order of the others
+func (p *Physics) removeJoint(i int) {
+	j := p.joints[i]
+	key := makePairKey(j.a, j.b)
+	if p.jointPairs[key]--; p.jointPairs[key] <= 0 {
+		delete(p.jointPairs, key)
+	}
+	p.joints = append(p.joints[:i], p.joints[i+1:]...)
+}
+
+// jointed reports whether two bodies are held together by a joint
+func (p *Physics) jointed(a, b *body) bool {
+	return len(p.jointPairs) > 0 && p.jointPairs[makePairKey(a, b)] > 0
+}
+
+// toLocal rotates a world space direction into the local space of a body
+func (b *body) toLocal(direction mgl64.Vec3) mgl64.Vec3 {
+	return b.rotation.Transpose().Mul3x1(direction)
+}
+
+// simulated reports whether a joint takes part in the current step. A joint
+// touching a moving body wakes the other body up, so both always move together.
+func (p *Physics) simulated(j *joint) bool {
+	if j.a.dormant() && j.b.dormant() {
+		return false
+	}
+	p.wake(j.a)
+	p.wake(j.b)
+	return true
+}
+
+// prepare computes the anchors, effective masses and drift corrections of a
+// joint for the coming velocity iterations
+func (j *joint) prepare(deltaTime float64) {
+	a, b := j.a, j.b
+	j.rA = a.rotation.Mul3x1(j.localA)
+	j.rB = b.rotation.Mul3x1(j.localB)
+	j.impulse = mgl64.Vec3{}
+
+	drift := b.Position.Add(j.rB).Sub(a.Position.Add(j.rA))
+	correction := jointBias / deltaTime
+
+	if j.Type == JointDistance {
+		length := drift.Len()
+		j.axis = mgl64.Vec3{0, 1, 0}
+		if length > 1e-9 {
+			j.axis = drift.Mul(1 / length)
+		}
+		armA, armB := j.rA.Cross(j.axis), j.rB.Cross(j.axis)
+		inverse := a.InverseMass + b.InverseMass +
+			armA.Dot(a.inverseInertiaWorld.Mul3x1(armA)) + armB.Dot(b.inverseInertiaWorld.Mul3x1(armB))
+		j.axisMass = 0
+		if inverse > 0 {
+			j.axisMass = 1 / inverse
+		}
+		j.bias = j.axis.Mul((length - j.Length) * correction)
+		return
+	}
+
+	// K = (mA + mB)·I - [rA]ₓ·IA·[rA]ₓ - [rB]ₓ·IB·[rB]ₓ
+	skewA, skewB := skew(j.rA), skew(j.rB)
+	k := mgl64.Ident3().Mul(a.InverseMass + b.InverseMass).
+		Sub(skewA.Mul3(a.inverseInertiaWorld).Mul3(skewA)).
+		Sub(skewB.Mul3(b.inverseInertiaWorld).Mul3(skewB))
+	j.pointMass = mgl64.Mat3{}
+	if k.Det() != 0 {
+		j.pointMass = k.Inv()
+	}
+	j.bias = drift.Mul(correction)
+
+	switch j.Type {
+	case JointHinge:
+		// The rotation that would line the axis of B up with the axis of A
+		j.angular = a.rotation.Mul3x1(j.axisA).Cross(b.rotation.Mul3x1(j.axisB)).Mul(correction)
+	case JointFixed:
+		// The rotation of B away from its rest orientation relative to A
+		target := a.Orientation.Mul(j.rest)
+		drift := b.Orientation.Mul(target.Inverse())
+		if drift.W < 0 {
+			drift = drift.Scale(-1)
+		}
+		j.angular = drift.V.Mul(2 * correction)
+	}
+}
+
+// solveVelocity applies the impulses that stop the bodies of a joint from
+// moving apart at the anchors and, for hinges and fixed joints, from turning
+// in the directions the joint locks
+func (j *joint) solveVelocity() {
+	a, b := j.a, j.b
+
+	switch j.Type {
+	case JointDistance:
+		speed := b.velocityAt(j.rB).Sub(a.velocityAt(j.rA)).Dot(j.axis) + j.bias.Dot(j.axis)
+		j.applyImpulse(j.axis.Mul(-j.axisMass * speed))
+		return
+	default:
+		relative := b.velocityAt(j.rB).Sub(a.velocityAt(j.rA)).Add(j.bias)
+		j.applyImpulse(j.pointMass.Mul3x1(relative).Mul(-1))
+	}
+
+	switch j.Type {
+	case JointHinge:
+		// Only the spin around the hinge axis is left free
+		axis := a.rotation.Mul3x1(j.axisA)
+		tangents := tangentBasis(axis)
+		for _, direction := range tangents {
+			inverse := direction.Dot(a.inverseInertiaWorld.Mul3x1(direction)) + direction.Dot(b.inverseInertiaWorld.Mul3x1(direction))
+			if inverse <= 0 {
+				continue
+			}
+			speed := b.AngularVelocity.Sub(a.AngularVelocity).Add(j.angular).Dot(direction)
+			j.applyAngularImpulse(direction.Mul(-speed / inverse))
+		}
+	case JointFixed:
+		inverse := a.inverseInertiaWorld.Add(b.inverseInertiaWorld)
+		if inverse.Det() == 0 {
+			return
+		}
+		spin := b.AngularVelocity.Sub(a.AngularVelocity).Add(j.angular)
+		j.applyAngularImpulse(inverse.Inv().Mul3x1(spin).Mul(-1))
+	}
+}
+
+// applyImpulse pushes B by impulse at its anchor and A the opposite way
+func (j *joint) applyImpulse(impulse mgl64.Vec3) {
+	j.a.applyImpulse(impulse.Mul(-1), j.rA)
+	j.b.applyImpulse(impulse, j.rB)
+	j.impulse = j.impulse.Add(impulse)
+}
+
+// applyAngularImpulse turns B by impulse and A the opposite way
+func (j *joint) applyAngularImpulse(impulse mgl64.Vec3) {
+	j.a.AngularVelocity = j.a.AngularVelocity.Sub(j.a.inverseInertiaWorld.Mul3x1(impulse))
+	j.b.AngularVelocity = j.b.AngularVelocity.Add(j.b.inverseInertiaWorld.Mul3x1(impulse))
+}
+
+// breakJoints removes the joints of a step that had to hold more than their
+// break force, so they can be reported on the next tick
+func (p *Physics) breakJoints(joints []*joint, deltaTime float64) {
+	for _, j := range joints {
+		if j.BreakForce <= 0 {
+			continue
+		}
+		if force := j.impulse.Len() / deltaTime; force > j.BreakForce {
+			p.broken = append(p.broken, brokenJoint{id: j.id, a: j.a, b: j.b, force: force})
+			p.DestroyJoint(j.id)
+		}
+	}
+}
+
+// jointEvents reports the joints that broke since the last tick to the owners
+// of both of their bodies
+func (p *Physics) jointEvents(send func(pid *actor.PID, event any)) {
+	for _, broken := range p.broken {
+		send(broken.a.pid, EventJointBroken{PID: broken.a.pid, ID: broken.id, Other: broken.b.pid, Force: broken.force})
+		send(broken.b.pid, EventJointBroken{PID: broken.b.pid, ID: broken.id, Other: broken.a.pid, Force: broken.force})
+	}
+	p.broken = p.broken[:0]
+}
+
+// skew returns the matrix form of the cross product with v, so that
+// skew(v)·w equals v×w
+func skew(v mgl64.Vec3) mgl64.Mat3 {
+	// Column major
+	return mgl64.Mat3{
+		0, v.Z(), -v.Y(),
+		-v.Z(), 0, v.X(),
+		v.Y(), -v.X(), 0,
+	}
+}
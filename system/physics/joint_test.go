@@ -0,0 +1,124 @@
+package physics
+
+import (
+	"math"
+	"testing"
+
+	"github.com/anthdm/hollywood/actor"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+func TestDistanceJointSwingsLikeAPendulum(t *testing.T) {
+	p := newTestPhysics()
+
+	// Ice barely damps the swing
+	pivot := actor.NewPID("local", "pivot")
+	bob := actor.NewPID("local", "bob")
+	p.Register(pivot, EntityRigidBody{Position: mgl64.Vec3{0, 5, 0}, Scale: mgl64.Vec3{0.2, 0.2, 0.2}, BodyType: BodyStatic})
+	p.Register(bob, EntityRigidBody{Position: mgl64.Vec3{2, 5, 0}, Scale: mgl64.Vec3{0.5, 0.5, 0.5}, Material: &IceMaterial})
+	p.CreateJoint("rope", Joint{Type: JointDistance, A: pivot, B: bob, AnchorA: mgl64.Vec3{0, 5, 0}, AnchorB: mgl64.Vec3{2, 5, 0}})
+
+	lowest := math.Inf(1)
+	for i := 0; i < 256; i++ {
+		p.Step(FixedDeltaTime)
+		position := p.entities[bob].Position
+		lowest = math.Min(lowest, position.Y())
+		if length := position.Sub(mgl64.Vec3{0, 5, 0}).Len(); math.Abs(length-2) > 0.05 {
+			t.Fatalf("rope should keep its length of 2, got %f after %d steps", length, i)
+		}
+	}
+	if lowest > 3.1 {
+		t.Errorf("bob should swing down to the bottom of the arc, lowest y=%f", lowest)
+	}
+}
+
+func TestHingeJointOnlyTurnsAroundItsAxis(t *testing.T) {
+	p := newTestPhysics()
+
+	frame := actor.NewPID("local", "frame")
+	door := actor.NewPID("local", "door")
+	p.Register(frame, EntityRigidBody{Position: mgl64.Vec3{0, 2, 0}, Scale: mgl64.Vec3{0.2, 4, 0.2}, BodyType: BodyStatic})
+	p.Register(door, EntityRigidBody{Position: mgl64.Vec3{0.6, 2, 0}, Scale: mgl64.Vec3{1, 3, 0.1}, Material: &IceMaterial})
+	p.CreateJoint("hinge", Joint{Type: JointHinge, A: frame, B: door, AnchorA: mgl64.Vec3{0.1, 2, 0}, Axis: mgl64.Vec3{0, 1, 0}})
+
+	// Push the door open and try to tip it over at the same time
+	p.entities[door].AngularVelocity = mgl64.Vec3{2, 1, 0}
+	for i := 0; i < 128; i++ {
+		p.Step(FixedDeltaTime)
+	}
+
+	b := p.entities[door]
+	if up := b.rotation.Col(1); up.Y() < 0.99 {
+		t.Errorf("door should stay upright, its up axis is %v", up)
+	}
+	if b.rotation.Col(0).Sub(mgl64.Vec3{1, 0, 0}).Len() < 0.1 {
+		t.Error("door should have swung around the hinge")
+	}
+	if hinge := b.Position.Add(b.rotation.Mul3x1(mgl64.Vec3{-0.5, 0, 0})); hinge.Sub(mgl64.Vec3{0.1, 2, 0}).Len() > 0.05 {
+		t.Errorf("door edge should stay at the hinge, got %v", hinge)
+	}
+}
+
+func TestFixedJointWeldsBodiesTogether(t *testing.T) {
+	p := newTestPhysics()
+
+	left := actor.NewPID("local", "left")
+	right := actor.NewPID("local", "right")
+	p.Register(left, EntityRigidBody{Position: mgl64.Vec3{0, 3, 0}, Scale: mgl64.Vec3{1, 1, 1}})
+	p.Register(right, EntityRigidBody{Position: mgl64.Vec3{1, 3.5, 0}, Scale: mgl64.Vec3{1, 1, 1}})
+	p.CreateJoint("weld", Joint{Type: JointFixed, A: left, B: right, AnchorA: mgl64.Vec3{0.5, 3.25, 0}})
+
+	// Falling onto the floor with an overhang tips the pair over as one piece
+	for i := 0; i < 256*2; i++ {
+		p.Step(FixedDeltaTime)
+	}
+
+	a, b := p.entities[left], p.entities[right]
+	offset := a.toLocal(b.Position.Sub(a.Position))
+	if offset.Sub(mgl64.Vec3{1, 0.5, 0}).Len() > 0.05 {
+		t.Errorf("welded body should keep its offset, got %v", offset)
+	}
+	relative := a.Orientation.Inverse().Mul(b.Orientation)
+	if math.Abs(relative.W) < 0.999 {
+		t.Errorf("welded body should keep its orientation, relative rotation %v", relative)
+	}
+}
+
+func TestJointBreaksAboveBreakForceAndNotifiesBothOwners(t *testing.T) {
+	p := newTestPhysics()
+
+	pivot := actor.NewPID("local", "pivot")
+	lamp := actor.NewPID("local", "lamp")
+	p.Register(pivot, EntityRigidBody{Position: mgl64.Vec3{0, 5, 0}, Scale: mgl64.Vec3{0.2, 0.2, 0.2}, BodyType: BodyStatic})
+	p.Register(lamp, EntityRigidBody{Position: mgl64.Vec3{0, 4, 0}, Scale: mgl64.Vec3{0.5, 0.5, 0.5}, Mass: 2})
+	p.CreateJoint("chain", Joint{Type: JointBallSocket, A: pivot, B: lamp, AnchorA: mgl64.Vec3{0, 5, 0}, BreakForce: 50})
+
+	// Hanging still, the joint only holds the weight of the lamp
+	for i := 0; i < 64; i++ {
+		p.Step(FixedDeltaTime)
+	}
+	if len(p.joints) != 1 {
+		t.Fatal("joint should hold the weight of the lamp")
+	}
+	if y := p.entities[lamp].Position.Y(); math.Abs(y-4) > 0.05 {
+		t.Errorf("lamp should hang below the pivot, got y=%f", y)
+	}
+
+	// Yanking the lamp down needs more than the break force
+	p.entities[lamp].Velocity = mgl64.Vec3{0, -10, 0}
+	p.Step(FixedDeltaTime)
+	if len(p.joints) != 0 || p.jointed(p.entities[pivot], p.entities[lamp]) {
+		t.Fatal("joint should have broken")
+	}
+
+	events := make(map[*actor.PID]EventJointBroken)
+	p.jointEvents(func(pid *actor.PID, event any) {
+		events[pid] = event.(EventJointBroken)
+	})
+	if events[pivot].ID != "chain" || events[pivot].Other != lamp {
+		t.Errorf("pivot owner should be told the chain broke, got %+v", events[pivot])
+	}
+	if events[lamp].ID != "chain" || events[lamp].Other != pivot || events[lamp].Force <= 50 {
+		t.Errorf("lamp owner should be told the chain broke, got %+v", events[lamp])
+	}
+}
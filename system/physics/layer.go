@@ -0,0 +1,57 @@
+package physics
+
+import (
+	"fmt"
+	"sync"
+)
+
+// maxLayers is the number of layer bits available to the registry
+const maxLayers = 32
+
+// layers maps the names of collision layers to their bits. Entities are built
+// by other actors than the physics system, so the registry is shared and locked.
+var layers = struct {
+	sync.Mutex
+	bits map[string]uint32
+}{bits: map[string]uint32{"default": LayerDefault}}
+
+// RegisterLayer returns the bit of a named collision layer, giving it the next
+// free bit the first time the name is seen. "default" is LayerDefault.
+func RegisterLayer(name string) (uint32, error) {
+	layers.Lock()
+	defer layers.Unlock()
+
+	if bit, ok := layers.bits[name]; ok {
+		return bit, nil
+	}
+	if len(layers.bits) == maxLayers {
+		return 0, fmt.Errorf("cannot register layer %s: all %d layers are taken", name, maxLayers)
+	}
+
+	bit := uint32(1) << len(layers.bits)
+	layers.bits[name] = bit
+	return bit, nil
+}
+
+// LayerByName returns the bit of a registered collision layer
+func LayerByName(name string) (uint32, bool) {
+	layers.Lock()
+	defer layers.Unlock()
+
+	bit, ok := layers.bits[name]
+	return bit, ok
+}
+
+// LayerMask combines registered collision layers into a mask, for use as the
+// CollisionMask of a body or the LayerMask of a query
+func LayerMask(names ...string) (uint32, error) {
+	var mask uint32
+	for _, name := range names {
+		bit, ok := LayerByName(name)
+		if !ok {
+			return 0, fmt.Errorf("layer %s is not registered", name)
+		}
+		mask |= bit
+	}
+	return mask, nil
+}
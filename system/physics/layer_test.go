@@ -0,0 +1,89 @@
+package physics
+
+import (
+	"testing"
+
+	"github.com/anthdm/hollywood/actor"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+func TestLayerRegistryGivesEveryNameItsOwnBit(t *testing.T) {
+	player, err := RegisterLayer("player")
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, _ := RegisterLayer("player")
+	projectile, _ := RegisterLayer("projectile")
+	if player != again || player == projectile || player == LayerDefault || player&(player-1) != 0 {
+		t.Errorf("expected distinct single bits, got player %b, again %b and projectile %b", player, again, projectile)
+	}
+
+	if mask, err := LayerMask("player", "default"); err != nil || mask != player|LayerDefault {
+		t.Errorf("expected a mask of player and default, got %b and %v", mask, err)
+	}
+	if _, err := LayerMask("missing"); err == nil {
+		t.Error("unknown layers should not make a mask")
+	}
+}
+
+func TestCollisionMasksFilterContactsTriggersAndQueries(t *testing.T) {
+	p := newTestPhysics()
+	playerLayer, _ := RegisterLayer("player")
+	projectileLayer, _ := RegisterLayer("projectile")
+
+	// Projectiles ignore the player they were fired from, but still hit crates
+	player := actor.NewPID("local", "player")
+	crate := actor.NewPID("local", "crate")
+	bullet := actor.NewPID("local", "bullet")
+	zone := actor.NewPID("local", "zone")
+	p.Register(player, EntityRigidBody{Position: mgl64.Vec3{0, 0.5, 0}, Scale: mgl64.Vec3{1, 1, 1}, Layer: playerLayer})
+	p.Register(crate, EntityRigidBody{Position: mgl64.Vec3{5, 0.5, 0}, Scale: mgl64.Vec3{1, 1, 1}})
+	p.Register(bullet, EntityRigidBody{
+		Position:      mgl64.Vec3{0, 0.5, 0},
+		Scale:         mgl64.Vec3{1, 1, 1},
+		Layer:         projectileLayer,
+		CollisionMask: ^playerLayer,
+	})
+
+	// The zone only detects players
+	p.Register(zone, EntityRigidBody{
+		Position:      mgl64.Vec3{5, 0.5, 0},
+		Scale:         mgl64.Vec3{3, 3, 3},
+		BodyType:      BodyStatic,
+		Sensor:        true,
+		CollisionMask: playerLayer,
+	})
+
+	touching := func(a, b *actor.PID) bool {
+		for _, c := range p.stepContacts {
+			if (c.a.pid == a && c.b.pid == b) || (c.a.pid == b && c.b.pid == a) {
+				return true
+			}
+		}
+		return false
+	}
+
+	p.Step(FixedDeltaTime)
+	if touching(bullet, player) {
+		t.Error("projectile should ignore the player")
+	}
+	for key := range p.triggers {
+		t.Errorf("zone should only detect players, got a trigger for %v", key)
+	}
+
+	p.entities[bullet].Position = mgl64.Vec3{5, 0.9, 0}
+	p.entities[player].Position = mgl64.Vec3{4, 0.5, 0}
+	p.Step(FixedDeltaTime)
+	if !touching(bullet, crate) {
+		t.Error("projectile should still hit the crate")
+	}
+	if len(p.triggers) != 1 {
+		t.Errorf("zone should detect the player, got %d triggers", len(p.triggers))
+	}
+
+	// Queries match the layer of the bodies against their mask
+	hits := p.Raycast(mgl64.Vec3{-5, 0.5, 0}, mgl64.Vec3{1, 0, 0}, 0, LayerDefault, true, nil)
+	if len(hits) != 1 || hits[0].PID != crate {
+		t.Errorf("raycast should skip the player and only hit the crate, got %+v", hits)
+	}
+}
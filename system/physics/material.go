@@ -0,0 +1,126 @@
+package physics
+
+import "math"
+
+// CombineMode decides how a property of two touching materials is merged.
+// When the materials disagree the mode with the highest value wins, so for
+// example a material using CombineMinimum always beats one using CombineAverage.
+type CombineMode int
+
+const (
+	CombineAverage CombineMode = iota
+	CombineMinimum
+	CombineMultiply
+	CombineMaximum
+)
+
+// PhysicsMaterial describes how the surface of a body reacts to contacts
+type PhysicsMaterial struct {
+	Restitution     float64 // Fraction of the normal velocity kept after a bounce
+	StaticFriction  float64 // Friction coefficient while the surfaces stick together
+	DynamicFriction float64 // Friction coefficient while the surfaces slide
+	LinearDamping   float64 // Per second damping of horizontal velocity, gravity is never damped
+	AngularDamping  float64 // Per second damping of angular velocity
+
+	FrictionCombine    CombineMode
+	RestitutionCombine CombineMode
+}
+
+var (
+	// DefaultMaterial is used by bodies registered without a material
+	DefaultMaterial = PhysicsMaterial{
+		Restitution:     0.3, // 30% bounce (70% energy loss)
+		StaticFriction:  0.6,
+		DynamicFriction: 0.5,
+		LinearDamping:   13.1, // About 5% per step at 256 Hz
+		AngularDamping:  0.05,
+	}
+
+	// CharacterMaterial never bounces, whatever surface it lands on
+	CharacterMaterial = PhysicsMaterial{
+		Restitution:        0,
+		StaticFriction:     0.6,
+		DynamicFriction:    0.5,
+		LinearDamping:      13.1,
+		AngularDamping:     0.05,
+		RestitutionCombine: CombineMinimum,
+	}
+
+	// IceMaterial keeps sliding bodies moving for a long time
+	IceMaterial = PhysicsMaterial{
+		Restitution:     0.05,
+		StaticFriction:  0.02,
+		DynamicFriction: 0.01,
+		LinearDamping:   0.2,
+		AngularDamping:  0.05,
+		FrictionCombine: CombineMinimum,
+	}
+
+	// RubberMaterial bounces off everything it hits
+	RubberMaterial = PhysicsMaterial{
+		Restitution:        0.8,
+		StaticFriction:     1.0,
+		DynamicFriction:    0.8,
+		LinearDamping:      2.0,
+		AngularDamping:     0.1,
+		RestitutionCombine: CombineMaximum,
+	}
+
+	// VehicleMaterial lets vehicles roll, its damping only acts as air drag
+	// giving them a top speed
+	VehicleMaterial = PhysicsMaterial{
+		Restitution:     0.1,
+		StaticFriction:  0.6,
+		DynamicFriction: 0.5,
+		LinearDamping:   0.5,
+		AngularDamping:  0.5,
+	}
+
+	// MudMaterial swallows bounces and stops sliding bodies quickly
+	MudMaterial = PhysicsMaterial{
+		Restitution:        0,
+		StaticFriction:     1.2,
+		DynamicFriction:    1.0,
+		LinearDamping:      30,
+		AngularDamping:     2.0,
+		FrictionCombine:    CombineMaximum,
+		RestitutionCombine: CombineMinimum,
+	}
+)
+
+// groundMaterial is the surface of the floor plane at y=0. Terrain uses the
+// material of its body instead.
+var groundMaterial = DefaultMaterial
+
+// combinedMaterial holds the properties used to resolve a contact between two materials
+type combinedMaterial struct {
+	restitution     float64
+	staticFriction  float64
+	dynamicFriction float64
+}
+
+// combineMaterials merges the surface properties of two touching materials
+func combineMaterials(a, b PhysicsMaterial) combinedMaterial {
+	frictionMode := max(a.FrictionCombine, b.FrictionCombine)
+	restitutionMode := max(a.RestitutionCombine, b.RestitutionCombine)
+
+	return combinedMaterial{
+		restitution:     combine(a.Restitution, b.Restitution, restitutionMode),
+		staticFriction:  combine(a.StaticFriction, b.StaticFriction, frictionMode),
+		dynamicFriction: combine(a.DynamicFriction, b.DynamicFriction, frictionMode),
+	}
+}
+
+// combine merges two values of a material property with the given mode
+func combine(a, b float64, mode CombineMode) float64 {
+	switch mode {
+	case CombineMinimum:
+		return math.Min(a, b)
+	case CombineMultiply:
+		return a * b
+	case CombineMaximum:
+		return math.Max(a, b)
+	default:
+		return (a + b) / 2
+	}
+}
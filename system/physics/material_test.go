@@ -0,0 +1,124 @@
+package physics
+
+import (
+	"math"
+	"testing"
+
+	"github.com/anthdm/hollywood/actor"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+func TestCombineMaterialsUsesHighestCombineMode(t *testing.T) {
+	surface := combineMaterials(RubberMaterial, CharacterMaterial)
+	if surface.restitution != RubberMaterial.Restitution {
+		t.Fatalf("restitution = %v, want the rubber maximum %v", surface.restitution, RubberMaterial.Restitution)
+	}
+
+	surface = combineMaterials(IceMaterial, DefaultMaterial)
+	if surface.dynamicFriction != IceMaterial.DynamicFriction {
+		t.Fatalf("dynamic friction = %v, want the ice minimum %v", surface.dynamicFriction, IceMaterial.DynamicFriction)
+	}
+
+	surface = combineMaterials(DefaultMaterial, DefaultMaterial)
+	if surface.restitution != DefaultMaterial.Restitution {
+		t.Fatalf("restitution = %v, want %v", surface.restitution, DefaultMaterial.Restitution)
+	}
+}
+
+// bounceHeight drops a crate of the given material on the floor and returns
+// the highest its bottom gets after the first bounce
+func bounceHeight(material PhysicsMaterial) float64 {
+	p := newTestPhysics()
+	crate := actor.NewPID("local", "crate")
+	p.Register(crate, EntityRigidBody{Position: mgl64.Vec3{0, 3.5, 0}, Scale: mgl64.Vec3{1, 1, 1}, Material: &material})
+
+	b := p.entities[crate]
+	bounced, height := false, 0.0
+	for i := 0; i < 512; i++ {
+		p.Step(FixedDeltaTime)
+		if b.Velocity.Y() > 0 {
+			bounced = true
+		}
+		if bounced {
+			height = math.Max(height, b.Position.Y()-0.5)
+		}
+	}
+	return height
+}
+
+func TestRestitutionChangesBounceHeight(t *testing.T) {
+	dull, rubber := bounceHeight(DefaultMaterial), bounceHeight(RubberMaterial)
+
+	// Dropped from 3 units, the bottom rises back to about restitution squared times that
+	if math.Abs(rubber-3*0.8*0.8) > 0.3 {
+		t.Errorf("rubber should bounce back to about %f, got %f", 3*0.8*0.8, rubber)
+	}
+	if math.Abs(dull-3*0.3*0.3) > 0.1 {
+		t.Errorf("the default material should barely bounce back to about %f, got %f", 3*0.3*0.3, dull)
+	}
+}
+
+// slideDistance launches a crate of the given material along the floor and
+// returns how far it slid and its speed after two seconds
+func slideDistance(material PhysicsMaterial) (float64, float64) {
+	p := newTestPhysics()
+	crate := actor.NewPID("local", "crate")
+	p.Register(crate, EntityRigidBody{Position: mgl64.Vec3{0, 0.5, 0}, Velocity: mgl64.Vec3{4, 0, 0}, Scale: mgl64.Vec3{1, 1, 1}, Material: &material})
+
+	b := p.entities[crate]
+	for i := 0; i < 512; i++ {
+		p.Step(FixedDeltaTime)
+	}
+	return b.Position.X(), b.Velocity.X()
+}
+
+func TestFrictionStopsSlidingBodies(t *testing.T) {
+	// Without damping only friction can slow the crates down
+	rough, slippery := DefaultMaterial, DefaultMaterial
+	rough.LinearDamping = 0
+	slippery.LinearDamping = 0
+	slippery.StaticFriction, slippery.DynamicFriction = 0, 0
+	slippery.FrictionCombine = CombineMinimum
+
+	// A friction of 0.5 stops a crate at 4 units per second after v²/2µg units
+	distance, speed := slideDistance(rough)
+	if expected := 16 / (2 * 0.5 * -DefaultGravity.Y()); speed != 0 || math.Abs(distance-expected) > 0.05 {
+		t.Errorf("friction should stop the crate after about %f units, it slid %f and still moves at %f", expected, distance, speed)
+	}
+
+	distance, speed = slideDistance(slippery)
+	if math.Abs(speed-4) > 1e-6 || math.Abs(distance-8) > 0.1 {
+		t.Errorf("a frictionless crate should keep sliding at 4, it slid %f and moves at %f", distance, speed)
+	}
+}
+
+func TestDampingSlowsBodies(t *testing.T) {
+	p := newTestPhysics()
+	p.SetGravity(mgl64.Vec3{})
+
+	damped, free := DefaultMaterial, DefaultMaterial
+	damped.LinearDamping, damped.AngularDamping = 2, 2
+	free.LinearDamping, free.AngularDamping = 0, 0
+
+	// Far above the floor so that nothing but damping acts on them
+	slowed, kept := actor.NewPID("local", "slowed"), actor.NewPID("local", "kept")
+	velocity, spin := mgl64.Vec3{4, 0, 0}, mgl64.Vec3{0, 3, 0}
+	p.Register(slowed, EntityRigidBody{Position: mgl64.Vec3{0, 50, 0}, Velocity: velocity, AngularVelocity: spin, Scale: mgl64.Vec3{1, 1, 1}, Material: &damped})
+	p.Register(kept, EntityRigidBody{Position: mgl64.Vec3{0, 50, 20}, Velocity: velocity, AngularVelocity: spin, Scale: mgl64.Vec3{1, 1, 1}, Material: &free})
+
+	for i := 0; i < 256; i++ {
+		p.Step(FixedDeltaTime)
+	}
+
+	// A per second damping of 2 keeps e^-2 of the velocity after a second
+	a, b := p.entities[slowed], p.entities[kept]
+	if expected := 4 * math.Exp(-2); math.Abs(a.Velocity.X()-expected) > 1e-6 {
+		t.Errorf("expected the damped body to slow to %f, got %f", expected, a.Velocity.X())
+	}
+	if expected := 3 * math.Exp(-2); math.Abs(a.AngularVelocity.Y()-expected) > 1e-6 {
+		t.Errorf("expected the damped body to spin down to %f, got %f", expected, a.AngularVelocity.Y())
+	}
+	if b.Velocity != velocity || b.AngularVelocity != spin {
+		t.Errorf("an undamped body should keep its velocity, got %v and %v", b.Velocity, b.AngularVelocity)
+	}
+}
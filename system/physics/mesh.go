@@ -0,0 +1,86 @@
+package physics
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// triangleMesh is the triangle mesh of a static body in world space. Its
+// triangles live in a spatial hash of their own, so a body resting on a large
+// mesh only tests the few triangles under it.
+type triangleMesh struct {
+	triangles [][3]mgl64.Vec3
+	hash      *SpatialHash
+	extents   mgl64.Vec3 // Half extents of the box around the mesh, centered on the body
+}
+
+// newTriangleMesh places the triangles of a mesh given in model space into the
+// world, scaled, rotated and moved like the body using it
+func newTriangleMesh(triangles [][3]mgl64.Vec3, position mgl64.Vec3, rotation mgl64.Mat3, scale mgl64.Vec3) *triangleMesh {
+	mesh := &triangleMesh{
+		triangles: make([][3]mgl64.Vec3, len(triangles)),
+		hash:      NewSpatialHash(defaultCellSize),
+	}
+	for i, triangle := range triangles {
+		for v, vertex := range triangle {
+			world := rotation.Mul3x1(mulVec(vertex, scale))
+			mesh.triangles[i][v] = position.Add(world)
+			for axis := 0; axis < 3; axis++ {
+				mesh.extents[axis] = math.Max(mesh.extents[axis], math.Abs(world[axis]))
+			}
+		}
+		mesh.hash.Insert(triangleBounds(mesh.triangles[i]))
+	}
+	return mesh
+}
+
+// triangleBounds returns the AABB of a triangle, grown so that flat triangles
+// still overlap the bodies resting on them
+func triangleBounds(triangle [3]mgl64.Vec3) AABB {
+	margin := mgl64.Vec3{manifoldTolerance, manifoldTolerance, manifoldTolerance}
+	return AABB{
+		Min: minVec(triangle[0], minVec(triangle[1], triangle[2])).Sub(margin),
+		Max: maxVec(triangle[0], maxVec(triangle[1], triangle[2])).Add(margin),
+	}
+}
+
+// triangle returns a triangle of the mesh as a convex shape
+func (m *triangleMesh) triangle(i int32) convex {
+	return convex{rotation: mgl64.Ident3(), points: m.triangles[i][:]}
+}
+
+// meshContacts appends a contact for every triangle of the mesh of b that a
+// overlaps. Every triangle pushes on its own, so a body lying across several
+// triangles is held by all of them.
+func meshContacts(a, b *body, contacts []contact) []contact {
+	shape := a.convex()
+	b.shape.mesh.hash.Query(a.bounds(), func(id int32) {
+		triangle := b.shape.mesh.triangle(id)
+		normal, depth, ok := convexSeparation(&shape, &triangle)
+		if !ok {
+			return
+		}
+		c := contact{a: a, b: b, normal: normal, depth: depth, feature: id}
+		c.addClippedFeatures(&shape, &triangle)
+		contacts = append(contacts, c)
+	})
+	return contacts
+}
+
+// triangleSeparation is separation against a single triangle of the mesh of b
+func triangleSeparation(a, b *body, i int32) (mgl64.Vec3, float64, bool) {
+	shape, triangle := a.convex(), b.shape.mesh.triangle(i)
+	return convexSeparation(&shape, &triangle)
+}
+
+// meshOverlaps reports whether a overlaps any triangle of the mesh of b
+func meshOverlaps(a, b *body) bool {
+	found := false
+	b.shape.mesh.hash.Query(a.bounds(), func(id int32) {
+		if !found {
+			_, _, found = triangleSeparation(a, b, id)
+		}
+	})
+	return found
+}
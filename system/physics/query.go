@@ -0,0 +1,325 @@
+package physics
+
+import (
+	"math"
+	"sort"
+
+	"github.com/anthdm/hollywood/actor"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+const (
+	// defaultQueryDistance is used by queries sent without a MaxDistance
+	defaultQueryDistance = 1000.0
+
+	// queryMargin pads the boxes used to collect candidates, so that flat bodies
+	// such as the floor plane are found by queries ending right on their surface
+	queryMargin = 1e-6
+
+	// castIterations bounds the conservative advancement of a cast against a
+	// shape other than an axis-aligned box
+	castIterations = 32
+
+	// castTolerance is how close a cast has to get to a shape to count as
+	// touching it
+	castTolerance = 1e-6
+)
+
+// QueryHit describes a body hit by a raycast or shape cast
+type QueryHit struct {
+	PID      *actor.PID
+	Point    mgl64.Vec3 // World space point where the query touched the body
+	Normal   mgl64.Vec3 // Surface normal of the body at Point
+	Distance float64    // Distance travelled along the query direction
+}
+
+// Raycast returns the bodies hit by a ray, ordered by distance. When all is false
+// only the closest hit is returned. A layerMask of zero matches every layer.
+// Sensors are never hit.
+func (p *Physics) Raycast(origin, direction mgl64.Vec3, maxDistance float64, layerMask uint32, all bool, ignore *actor.PID) []QueryHit {
+	return p.cast(origin, direction, mgl64.Vec3{}, maxDistance, layerMask, all, ignore)
+}
+
+// ShapeCast sweeps an axis-aligned box with the given half extents along a ray
+// and returns the bodies it would touch, ordered by distance. The hit point is
+// the point of the body closest to the box when they first touch.
+func (p *Physics) ShapeCast(origin, direction, halfExtents mgl64.Vec3, maxDistance float64, layerMask uint32, all bool, ignore *actor.PID) []QueryHit {
+	return p.cast(origin, direction, halfExtents, maxDistance, layerMask, all, ignore)
+}
+
+// cast sweeps a box along a ray, a box without extents being a plain ray. The
+// part of the ray crossing occupied cells is walked one cell at a time and every
+// piece is queried against the broadphases, so only the bodies near the ray are
+// tested and a closest hit query stops at the first piece that produced a hit.
+func (p *Physics) cast(origin, direction, halfExtents mgl64.Vec3, maxDistance float64, layerMask uint32, all bool, ignore *actor.PID) []QueryHit {
+	if direction.Len() == 0 {
+		return nil
+	}
+	direction = direction.Normalize()
+	if maxDistance <= 0 {
+		maxDistance = defaultQueryDistance
+	}
+
+	var hits []QueryHit
+	limit := maxDistance
+	swept := convex{position: origin, rotation: mgl64.Ident3(), half: halfExtents}
+	visited := make(map[*body]struct{})
+	test := func(b *body) {
+		if _, ok := visited[b]; ok {
+			return
+		}
+		visited[b] = struct{}{}
+
+		if b.Sensor || (ignore != nil && b.pid.Equals(ignore)) {
+			return
+		}
+		if layerMask != 0 && b.layer()&layerMask == 0 {
+			return
+		}
+
+		// Boxes are hit exactly: rays in the space of the box, swept boxes as a ray
+		// against the box grown by the swept extents while both are axis-aligned.
+		// Other shapes and the triangles of meshes are cast against with GJK.
+		// Terrain is only hit by the center of the swept box.
+		var distance float64
+		var normal, point mgl64.Vec3
+		var ok bool
+		switch {
+		case b.shape.heightfield != nil:
+			distance, normal, ok = b.intersectTerrain(origin, direction, limit)
+			point = origin.Add(direction.Mul(distance))
+		case b.shape.mesh != nil:
+			distance, normal, point, ok = b.shape.mesh.cast(swept, direction, limit)
+		case b.shape.kind != ShapeBox || (!b.axisAligned && halfExtents != mgl64.Vec3{}):
+			shape := b.convex()
+			distance, normal, point, ok = castConvex(swept, direction, limit, &shape)
+		case halfExtents == (mgl64.Vec3{}):
+			distance, normal, ok = b.box().intersectRay(origin, direction)
+			point = origin.Add(direction.Mul(distance))
+		default:
+			distance, normal, ok = NewAABB(b.Position, b.halfExtents.Add(halfExtents)).intersectRay(origin, direction)
+			point = b.bounds().closestPoint(origin.Add(direction.Mul(distance)))
+		}
+		if !ok || distance > limit {
+			return
+		}
+
+		hits = append(hits, QueryHit{
+			PID:      b.pid,
+			Point:    point,
+			Normal:   normal,
+			Distance: distance,
+		})
+		if !all {
+			limit = distance
+		}
+	}
+
+	// Large bodies such as terrain are kept out of the grid, they are tested once
+	// against the whole cast rather than for every piece of it
+	margin := halfExtents.Add(mgl64.Vec3{queryMargin, queryMargin, queryMargin})
+	tip := origin.Add(direction.Mul(limit))
+	whole := AABB{Min: minVec(origin, tip).Sub(margin), Max: maxVec(origin, tip).Add(margin)}
+	p.broadphase.queryLarge(whole, func(id int32) { test(p.owners[id]) })
+	p.staticBroadphase.queryLarge(whole, func(id int32) { test(p.staticOwners[id]) })
+
+	// Only the part of the ray crossing occupied cells is walked
+	first, last, ok := p.occupiedRange(origin, direction, margin)
+	step := p.broadphase.cellSize
+	for start := first; ok && start <= math.Min(limit, last); start += step {
+		end := math.Min(start+step, limit)
+		from, to := origin.Add(direction.Mul(start)), origin.Add(direction.Mul(end))
+		segment := AABB{
+			Min: minVec(from, to).Sub(margin),
+			Max: maxVec(from, to).Add(margin),
+		}
+
+		p.broadphase.queryGrid(segment, func(id int32) { test(p.owners[id]) })
+		p.staticBroadphase.queryGrid(segment, func(id int32) { test(p.staticOwners[id]) })
+	}
+
+	sort.Slice(hits, func(i, j int) bool { return hits[i].Distance < hits[j].Distance })
+	if !all && len(hits) > 1 {
+		hits = hits[:1]
+	}
+
+	return hits
+}
+
+// occupiedRange returns the distances along a normalized ray between which it
+// crosses the occupied cells of either broadphase grown by the margin, or false
+// when it misses them all
+func (p *Physics) occupiedRange(origin, direction, margin mgl64.Vec3) (float64, float64, bool) {
+	var bounds AABB
+	found := false
+	for _, hash := range []*SpatialHash{p.broadphase, p.staticBroadphase} {
+		occupied, ok := hash.occupied()
+		if !ok {
+			continue
+		}
+		if found {
+			occupied = AABB{Min: minVec(bounds.Min, occupied.Min), Max: maxVec(bounds.Max, occupied.Max)}
+		}
+		bounds, found = occupied, true
+	}
+	if !found {
+		return 0, 0, false
+	}
+
+	bounds = AABB{Min: bounds.Min.Sub(margin), Max: bounds.Max.Add(margin)}
+	return bounds.rayInterval(origin, direction)
+}
+
+// castConvex returns how far a shape travels along a normalized direction
+// before it touches another one, with the surface normal and the point of the
+// other shape where they touch. Like timeOfImpact it advances by the distance
+// GJK measures between both shapes, which can never overshoot. A shape starting
+// inside the other one hits it at distance zero, facing back along the ray.
+func castConvex(shape convex, direction mgl64.Vec3, maxDistance float64, target *convex) (float64, mgl64.Vec3, mgl64.Vec3, bool) {
+	from := shape.position
+	distance := 0.0
+	var normal, point mgl64.Vec3
+	for i := 0; i < castIterations; i++ {
+		shape.position = from.Add(direction.Mul(distance))
+		result := gjk(&shape, target)
+		if result.overlap && distance == 0 {
+			return 0, direction.Mul(-1), from, true
+		}
+		if result.overlap {
+			return distance, normal, point, true
+		}
+
+		gap := result.distance - shape.radius - target.radius
+		if gap < 0 && distance == 0 {
+			return 0, direction.Mul(-1), from, true
+		}
+		_, closest := result.simplex.witnesses()
+		normal = result.normal.Mul(-1)
+		point = closest.Add(normal.Mul(target.radius))
+		if gap <= castTolerance {
+			return distance, normal, point, true
+		}
+
+		// Moving away from the closest point, the shapes only get further apart
+		closing := direction.Dot(result.normal)
+		if closing <= 0 {
+			return 0, mgl64.Vec3{}, mgl64.Vec3{}, false
+		}
+		distance += gap / closing
+		if distance > maxDistance {
+			return 0, mgl64.Vec3{}, mgl64.Vec3{}, false
+		}
+	}
+	return distance, normal, point, true
+}
+
+// cast returns the closest triangle of the mesh a shape touches when moving
+// along a normalized direction, like castConvex. Only the triangles around the
+// path of the shape are found through the hash of the mesh and tested.
+func (m *triangleMesh) cast(shape convex, direction mgl64.Vec3, maxDistance float64) (float64, mgl64.Vec3, mgl64.Vec3, bool) {
+	end := shape.position.Add(direction.Mul(maxDistance))
+	margin := shape.half.Add(mgl64.Vec3{queryMargin, queryMargin, queryMargin})
+	path := AABB{
+		Min: minVec(shape.position, end).Sub(margin),
+		Max: maxVec(shape.position, end).Add(margin),
+	}
+
+	var normal, point mgl64.Vec3
+	distance, found := maxDistance, false
+	m.hash.Query(path, func(id int32) {
+		triangle := m.triangle(id)
+		if d, n, p, ok := castConvex(shape, direction, distance, &triangle); ok && d <= distance {
+			distance, normal, point, found = d, n, p, true
+		}
+	})
+	return distance, normal, point, found
+}
+
+// intersectRay returns the distance along a normalized ray to the box and the
+// surface normal where the ray enters it. A ray starting inside the box hits it
+// at distance zero, facing back along the ray.
+func (a AABB) intersectRay(origin, direction mgl64.Vec3) (float64, mgl64.Vec3, bool) {
+	entry, exit := math.Inf(-1), math.Inf(1)
+	var normal mgl64.Vec3
+	for axis := 0; axis < 3; axis++ {
+		if direction[axis] == 0 {
+			if origin[axis] < a.Min[axis] || origin[axis] > a.Max[axis] {
+				return 0, mgl64.Vec3{}, false
+			}
+			continue
+		}
+
+		near := (a.Min[axis] - origin[axis]) / direction[axis]
+		far := (a.Max[axis] - origin[axis]) / direction[axis]
+		side := -1.0
+		if near > far {
+			near, far = far, near
+			side = 1
+		}
+
+		if near > entry {
+			entry = near
+			normal = mgl64.Vec3{}
+			normal[axis] = side
+		}
+		exit = math.Min(exit, far)
+		if entry > exit {
+			return 0, mgl64.Vec3{}, false
+		}
+	}
+
+	if exit < 0 {
+		return 0, mgl64.Vec3{}, false
+	}
+	if entry < 0 {
+		return 0, direction.Mul(-1), true
+	}
+
+	return entry, normal, true
+}
+
+// rayInterval returns the distances along a normalized ray where it enters and
+// leaves the box, the entry being clamped to the origin of the ray
+func (a AABB) rayInterval(origin, direction mgl64.Vec3) (float64, float64, bool) {
+	entry, exit := 0.0, math.Inf(1)
+	for axis := 0; axis < 3; axis++ {
+		if direction[axis] == 0 {
+			if origin[axis] < a.Min[axis] || origin[axis] > a.Max[axis] {
+				return 0, 0, false
+			}
+			continue
+		}
+
+		near := (a.Min[axis] - origin[axis]) / direction[axis]
+		far := (a.Max[axis] - origin[axis]) / direction[axis]
+		if near > far {
+			near, far = far, near
+		}
+		entry = math.Max(entry, near)
+		exit = math.Min(exit, far)
+		if entry > exit {
+			return 0, 0, false
+		}
+	}
+
+	return entry, exit, true
+}
+
+// closestPoint returns the point of the box closest to the given point
+func (a AABB) closestPoint(point mgl64.Vec3) mgl64.Vec3 {
+	return mgl64.Vec3{
+		math.Max(a.Min[0], math.Min(point[0], a.Max[0])),
+		math.Max(a.Min[1], math.Min(point[1], a.Max[1])),
+		math.Max(a.Min[2], math.Min(point[2], a.Max[2])),
+	}
+}
+
+// minVec returns the component-wise minimum of two vectors
+func minVec(a, b mgl64.Vec3) mgl64.Vec3 {
+	return mgl64.Vec3{math.Min(a[0], b[0]), math.Min(a[1], b[1]), math.Min(a[2], b[2])}
+}
+
+// maxVec returns the component-wise maximum of two vectors
+func maxVec(a, b mgl64.Vec3) mgl64.Vec3 {
+	return mgl64.Vec3{math.Max(a[0], b[0]), math.Max(a[1], b[1]), math.Max(a[2], b[2])}
+}
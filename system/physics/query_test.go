@@ -0,0 +1,146 @@
+package physics
+
+import (
+	"math"
+	"testing"
+
+	"github.com/anthdm/hollywood/actor"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+func TestRaycastReturnsClosestOrAllHits(t *testing.T) {
+	p := newTestPhysics()
+
+	near := actor.NewPID("local", "near")
+	far := actor.NewPID("local", "far")
+	hidden := actor.NewPID("local", "hidden")
+	p.Register(near, EntityRigidBody{Position: mgl64.Vec3{5, 1, 0}, Scale: mgl64.Vec3{1, 1, 1}})
+	p.Register(far, EntityRigidBody{Position: mgl64.Vec3{20, 1, 0}, Scale: mgl64.Vec3{1, 1, 1}, BodyType: BodyStatic})
+	p.Register(hidden, EntityRigidBody{Position: mgl64.Vec3{10, 1, 0}, Scale: mgl64.Vec3{1, 1, 1}, Layer: 1 << 3})
+
+	hits := p.Raycast(mgl64.Vec3{0, 1, 0}, mgl64.Vec3{1, 0, 0}, 100, LayerDefault, false, nil)
+	if len(hits) != 1 || hits[0].PID != near {
+		t.Fatalf("expected only the near body, got %v", hits)
+	}
+	if math.Abs(hits[0].Distance-4.5) > 1e-9 || hits[0].Normal != (mgl64.Vec3{-1, 0, 0}) {
+		t.Errorf("expected a hit at 4.5 facing -X, got %v", hits[0])
+	}
+	if hits[0].Point.Sub(mgl64.Vec3{4.5, 1, 0}).Len() > 1e-9 {
+		t.Errorf("unexpected hit point %v", hits[0].Point)
+	}
+
+	hits = p.Raycast(mgl64.Vec3{0, 1, 0}, mgl64.Vec3{1, 0, 0}, 100, 0, true, near)
+	if len(hits) != 2 || hits[0].PID != hidden || hits[1].PID != far {
+		t.Fatalf("expected the hidden and far bodies in order, got %v", hits)
+	}
+
+	hits = p.Raycast(mgl64.Vec3{0, 1, 0}, mgl64.Vec3{1, 0, 0}, 3, 0, true, nil)
+	if len(hits) != 0 {
+		t.Errorf("bodies past the max distance should not be hit, got %v", hits)
+	}
+}
+
+func TestShapeCastHitsBodiesBesideTheRay(t *testing.T) {
+	p := newTestPhysics()
+
+	wall := actor.NewPID("local", "wall")
+	p.Register(wall, EntityRigidBody{Position: mgl64.Vec3{5, 1.8, 0}, Scale: mgl64.Vec3{1, 1, 1}})
+
+	if hits := p.Raycast(mgl64.Vec3{0, 1, 0}, mgl64.Vec3{1, 0, 0}, 10, 0, false, nil); len(hits) != 0 {
+		t.Fatalf("ray should pass below the wall, got %v", hits)
+	}
+
+	hits := p.ShapeCast(mgl64.Vec3{0, 1, 0}, mgl64.Vec3{1, 0, 0}, mgl64.Vec3{0.5, 0.5, 0.5}, 10, 0, false, nil)
+	if len(hits) != 1 || hits[0].PID != wall {
+		t.Fatalf("expected the box to hit the wall, got %v", hits)
+	}
+	if math.Abs(hits[0].Distance-4) > 1e-9 {
+		t.Errorf("expected the box to touch the wall after 4 units, got %f", hits[0].Distance)
+	}
+}
+
+func TestCastsHitTheShapeRatherThanItsBox(t *testing.T) {
+	p := newTestPhysics()
+
+	ball := actor.NewPID("local", "ball")
+	p.Register(ball, EntityRigidBody{Position: mgl64.Vec3{5, 1, 0}, Scale: mgl64.Vec3{2, 2, 2}, Shape: SphereShape(1), BodyType: BodyStatic})
+
+	// The corner of the box around the ball is empty
+	if hits := p.Raycast(mgl64.Vec3{0, 1.9, 0.9}, mgl64.Vec3{1, 0, 0}, 10, 0, false, nil); len(hits) != 0 {
+		t.Fatalf("ray should pass beside the ball, got %v", hits)
+	}
+
+	hits := p.Raycast(mgl64.Vec3{0, 1.9, 0}, mgl64.Vec3{1, 0, 0}, 10, 0, false, nil)
+	if len(hits) != 1 || math.Abs(hits[0].Distance-(5-math.Sqrt(0.19))) > 1e-4 {
+		t.Fatalf("expected the ray to hit the ball at %f, got %v", 5-math.Sqrt(0.19), hits)
+	}
+	if hits[0].Normal.Sub(mgl64.Vec3{-math.Sqrt(0.19), 0.9, 0}).Len() > 1e-3 {
+		t.Errorf("expected the normal of the ball at the hit, got %v", hits[0].Normal)
+	}
+
+	// The lower front edge of the swept box reaches the ball first
+	hits = p.ShapeCast(mgl64.Vec3{0, 2.1, 0}, mgl64.Vec3{1, 0, 0}, mgl64.Vec3{0.25, 0.25, 0.25}, 10, 0, false, nil)
+	if expected := 5 - math.Sqrt(1-0.85*0.85) - 0.25; len(hits) != 1 || math.Abs(hits[0].Distance-expected) > 1e-4 {
+		t.Fatalf("expected the box to touch the ball after %f, got %v", expected, hits)
+	}
+	if hits[0].Point.Sub(mgl64.Vec3{5, 1, 0}).Len()-1 > 1e-4 {
+		t.Errorf("hit point %v should be on the ball", hits[0].Point)
+	}
+}
+
+func TestCastsHitTheTrianglesOfMeshes(t *testing.T) {
+	p := newTestPhysics()
+
+	ramp := actor.NewPID("local", "ramp")
+	triangles := [][3]mgl64.Vec3{{{0, 0, 0}, {4, 0, 0}, {4, 0, 4}}}
+	p.Register(ramp, EntityRigidBody{Scale: mgl64.Vec3{1, 1, 1}, Shape: TriangleMeshShape(triangles), BodyType: BodyStatic})
+
+	down := mgl64.Vec3{0, -1, 0}
+	if hits := p.Raycast(mgl64.Vec3{1, 5, 3}, down, 10, 0, false, nil); len(hits) != 0 {
+		t.Fatalf("ray should pass beside the triangle, got %v", hits)
+	}
+
+	hits := p.Raycast(mgl64.Vec3{3, 5, 1}, down, 10, 0, false, nil)
+	if len(hits) != 1 || hits[0].PID != ramp || math.Abs(hits[0].Distance-5) > 1e-4 {
+		t.Fatalf("expected the ray to hit the triangle after 5, got %v", hits)
+	}
+	if hits[0].Normal.Sub(mgl64.Vec3{0, 1, 0}).Len() > 1e-6 {
+		t.Errorf("expected the triangle to face up, got %v", hits[0].Normal)
+	}
+
+	hits = p.ShapeCast(mgl64.Vec3{3, 5, 1}, down, mgl64.Vec3{0.5, 0.5, 0.5}, 10, 0, false, nil)
+	if len(hits) != 1 || math.Abs(hits[0].Distance-4.5) > 1e-4 {
+		t.Fatalf("expected the box to land on the triangle after 4.5, got %v", hits)
+	}
+}
+
+func TestCastsOnlyWalkTheOccupiedCells(t *testing.T) {
+	p := newTestPhysics()
+
+	crate := actor.NewPID("local", "crate")
+	terrain := actor.NewPID("local", "terrain")
+	p.Register(crate, EntityRigidBody{Position: mgl64.Vec3{5, 1, 0}, Scale: mgl64.Vec3{1, 1, 1}})
+	flat := NewHeightfield(81, 81, 0.5, func(x, z float64) float64 { return 0 })
+	p.Register(terrain, EntityRigidBody{Position: mgl64.Vec3{0, -1, 0}, Shape: HeightfieldShape(flat)})
+
+	// The ray only crosses the cells of the crate, far from where it starts
+	origin := mgl64.Vec3{-400, 1, 0}
+	first, last, ok := p.occupiedRange(origin, mgl64.Vec3{1, 0, 0}, mgl64.Vec3{})
+	if !ok || first < 403 || last > 407 {
+		t.Fatalf("expected the walk to be clipped around the crate, got %f to %f", first, last)
+	}
+
+	hits := p.Raycast(origin, mgl64.Vec3{1, 0, 0}, 0, 0, false, nil)
+	if len(hits) != 1 || hits[0].PID != crate || math.Abs(hits[0].Distance-404.5) > 1e-9 {
+		t.Fatalf("expected the crate after 404.5, got %v", hits)
+	}
+
+	// The terrain is too large for the grid, it is hit without being walked
+	if _, _, ok := p.occupiedRange(mgl64.Vec3{-15, 5, 0}, mgl64.Vec3{0, -1, 0}, mgl64.Vec3{}); ok {
+		t.Fatal("a ray away from the crate should not cross occupied cells")
+	}
+	hits = p.Raycast(mgl64.Vec3{-15, 5, 0}, mgl64.Vec3{0, -1, 0}, 0, 0, true, nil)
+	if len(hits) != 1 || hits[0].PID != terrain || math.Abs(hits[0].Distance-6) > 1e-9 {
+		t.Fatalf("expected the terrain after 6, got %v", hits)
+	}
+}
@@ -0,0 +1,322 @@
+package physics
+
+import (
+	"math"
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+const (
+	// regionSize is the width of the columns the world is split into along X
+	// and Z. The contacts of every region are solved by a single worker while
+	// the other workers solve the other regions.
+	regionSize = 16.0
+
+	// minBatch is the fewest items handed to a worker at once, smaller batches
+	// cost more to schedule than to run
+	minBatch = 64
+
+	// serialRegion is the region of bodies whose contacts are solved by the
+	// physics actor itself after every parallel pass, such as jointed bodies
+	serialRegion = -1
+)
+
+// workerPool spreads the independent parts of a step over goroutines started
+// the first time there is work to share, and fed through a channel. Work is always split and merged the
+// same way, so the results do not depend on how many workers there are or on
+// how they are scheduled.
+type workerPool struct {
+	size int
+	jobs chan *workerJob // Runs handed to the goroutines, once per worker helping out
+	job  workerJob       // Reused by every run, as runs never overlap
+}
+
+// workerJob is a run shared by the workers, each of them taking its batches in
+// order until none is left
+type workerJob struct {
+	count, batch, batches int
+	work                  func(index, start, end int)
+	next                  atomic.Int32
+	done                  sync.WaitGroup
+}
+
+// newWorkerPool returns a pool of size workers, one per CPU when size is zero.
+// The goroutine calling run is one of them, so one fewer goroutine is started.
+// Worlds too small to ever split their work never start any.
+func newWorkerPool(size int) *workerPool {
+	if size <= 0 {
+		size = runtime.GOMAXPROCS(0)
+	}
+	return &workerPool{size: size}
+}
+
+// start starts the goroutines of the pool unless they are already running
+func (w *workerPool) start() {
+	if w.jobs != nil {
+		return
+	}
+	jobs := make(chan *workerJob, w.size-1)
+	w.jobs = jobs
+	for range w.size - 1 {
+		go func() {
+			for job := range jobs {
+				job.take()
+				job.done.Done()
+			}
+		}()
+	}
+}
+
+// stop ends the goroutines of the pool, which runs everything inline afterwards
+func (w *workerPool) stop() {
+	if w.jobs != nil {
+		close(w.jobs)
+		w.jobs = nil
+	}
+	w.size = 1
+}
+
+// batch returns how many of count items to hand to a worker at once, so that
+// every worker gets a few batches to even out the load. A single worker takes
+// everything in one batch.
+func (w *workerPool) batch(count int) int {
+	if w.size <= 1 {
+		return max(count, 1)
+	}
+	return max(minBatch, (count+4*w.size-1)/(4*w.size))
+}
+
+// run calls work for every batch of count items and waits until all of them
+// are done. Batches are handed out in order to whichever worker is free, and
+// run inline when there is a single worker or a single batch.
+func (w *workerPool) run(count, batch int, work func(index, start, end int)) {
+	batches := (count + batch - 1) / batch
+	if w.size <= 1 || batches <= 1 {
+		for i := 0; i < batches; i++ {
+			work(i, i*batch, min((i+1)*batch, count))
+		}
+		return
+	}
+
+	w.start()
+	job := &w.job
+	job.count, job.batch, job.batches, job.work = count, batch, batches, work
+	job.next.Store(0)
+	helpers := min(w.size, batches) - 1
+	job.done.Add(helpers)
+	for range helpers {
+		w.jobs <- job
+	}
+	job.take()
+	job.done.Wait()
+	job.work = nil
+}
+
+// take runs the batches of a job left to run
+func (job *workerJob) take() {
+	for {
+		i := int(job.next.Add(1)) - 1
+		if i >= job.batches {
+			return
+		}
+		job.work(i, i*job.batch, min((i+1)*job.batch, job.count))
+	}
+}
+
+// regionKey identifies a region by its column along X and Z
+type regionKey struct {
+	x, z int32
+}
+
+// region is a column of the world whose contacts are solved independently of
+// every other region. Regions are created the first time a body enters them and
+// kept in creation order, so they are always visited in the same order.
+type region struct {
+	key      regionKey
+	contacts []int32 // Contacts of the current step owned by the region
+	coupled  []int32 // Those sharing a body with another contact
+}
+
+// regionAt returns the index of the region containing a position. Bodies
+// mostly stay in the same region from one step to the next, so the region at
+// index previous is checked before looking the position up.
+func (p *Physics) regionAt(position mgl64.Vec3, previous int32) int32 {
+	key := regionKey{
+		x: int32(math.Floor(position.X() / regionSize)),
+		z: int32(math.Floor(position.Z() / regionSize)),
+	}
+	if previous >= 0 && int(previous) < len(p.regions) && p.regions[previous].key == key {
+		return previous
+	}
+	i, ok := p.regionIndex[key]
+	if !ok {
+		i = int32(len(p.regions))
+		p.regions = append(p.regions, &region{key: key})
+		p.regionIndex[key] = i
+	}
+	return i
+}
+
+// partition hands every contact to the region of the moving bodies it touches.
+// A body belongs to the region its center is in once it has been integrated,
+// so a body crossing a border is handed over to the neighbouring region on the
+// step it crosses it. Contacts between bodies of different regions, and those
+// of bodies held by joints, are left to the serial pass.
+func (p *Physics) partition(contacts []contact, joints []*joint) {
+	for _, r := range p.busyRegions {
+		r.contacts = r.contacts[:0]
+	}
+	p.busyRegions = p.busyRegions[:0]
+	p.crossContacts = p.crossContacts[:0]
+
+	for _, b := range p.active {
+		b.region = p.regionAt(b.Position, b.region)
+		b.contacts, b.touching = 0, false
+	}
+	for _, j := range joints {
+		j.a.region, j.b.region = serialRegion, serialRegion
+		j.a.touching, j.b.touching = true, true
+	}
+
+	for i := range contacts {
+		c := &contacts[i]
+		for _, b := range [2]*body{c.a, c.b} {
+			if b.InverseMass != 0 {
+				b.contacts++
+				b.touching = b.touching || !c.b.ground
+			}
+		}
+
+		owner := c.region()
+		if owner == serialRegion {
+			p.crossContacts = append(p.crossContacts, int32(i))
+			continue
+		}
+
+		r := p.regions[owner]
+		if len(r.contacts) == 0 {
+			p.busyRegions = append(p.busyRegions, r)
+		}
+		r.contacts = append(r.contacts, int32(i))
+	}
+}
+
+// region returns the region owning a contact. Static and kinematic bodies are
+// never changed by the solver, so only the moving bodies decide.
+func (c *contact) region() int32 {
+	switch {
+	case c.a.InverseMass == 0:
+		return c.b.region
+	case c.b.InverseMass == 0 || c.a.region == c.b.region:
+		return c.a.region
+	}
+	return serialRegion
+}
+
+// eachRegion calls solve with the contacts of every region holding some,
+// spreading the regions over the workers
+func (p *Physics) eachRegion(contacts []contact, solve func(c *contact)) {
+	p.workers.run(len(p.busyRegions), 1, func(index, _, _ int) {
+		for _, i := range p.busyRegions[index].contacts {
+			solve(&contacts[i])
+		}
+	})
+}
+
+// solveContacts resolves the contacts and joints of a step. Every pass goes
+// over the regions in parallel first, then over the joints and the contacts
+// crossing regions, so corrections made on either side of a border reach the
+// other side on the next pass like they would within a region.
+func (p *Physics) solveContacts(contacts []contact, joints []*joint, deltaTime float64) {
+	p.partition(contacts, joints)
+
+	// Every contact gets its share of the solver state of the step
+	count := 0
+	for i := range contacts {
+		count += contacts[i].pointCount
+	}
+	if cap(p.stepPoints) < count {
+		p.stepPoints = make([]manifoldPoint, count)
+	}
+	points := p.stepPoints[:count]
+	for i := range contacts {
+		c := &contacts[i]
+		c.solver, points = points[:c.pointCount:c.pointCount], points[c.pointCount:]
+	}
+
+	// Without joints or contacts crossing borders no correction ever leaves a
+	// region, so every region runs all of its passes at once and the workers
+	// are only handed the regions once. The results are the same as pass by pass.
+	if len(joints) == 0 && len(p.crossContacts) == 0 {
+		p.workers.run(len(p.busyRegions), 1, func(index, _, _ int) {
+			p.busyRegions[index].solve(contacts)
+		})
+		for i := range contacts {
+			contacts[i].finish()
+		}
+		return
+	}
+
+	// Velocities are solved first, then the remaining overlap is pushed apart
+	for _, j := range joints {
+		j.prepare(deltaTime)
+	}
+	p.eachRegion(contacts, (*contact).prepare)
+	for _, i := range p.crossContacts {
+		contacts[i].prepare()
+	}
+	for i := 0; i < solverIterations; i++ {
+		p.eachRegion(contacts, (*contact).solveVelocity)
+		for _, j := range joints {
+			j.solveVelocity()
+		}
+		for _, k := range p.crossContacts {
+			contacts[k].solveVelocity()
+		}
+	}
+	for i := range contacts {
+		contacts[i].finish()
+	}
+	p.breakJoints(joints, deltaTime)
+	for i := 0; i < solverIterations; i++ {
+		p.eachRegion(contacts, solvePosition)
+		for _, k := range p.crossContacts {
+			solvePosition(&contacts[k])
+		}
+	}
+}
+
+// solve runs every pass of the solver over the contacts of a region. The
+// first pass already settles a contact resting flat that is the only one of
+// the bodies it moves, as nothing changes them afterwards, so the other passes
+// only go over the contacts sharing a body.
+func (r *region) solve(contacts []contact) {
+	r.coupled = r.coupled[:0]
+	for _, i := range r.contacts {
+		c := &contacts[i]
+		c.prepare()
+		if !c.linear || c.a.contacts > 1 || c.b.contacts > 1 {
+			r.coupled = append(r.coupled, i)
+		}
+	}
+
+	for _, i := range r.contacts {
+		contacts[i].solveVelocity()
+	}
+	for range solverIterations - 1 {
+		for _, i := range r.coupled {
+			contacts[i].solveVelocity()
+		}
+	}
+	for _, i := range r.contacts {
+		solvePosition(&contacts[i])
+	}
+	for range solverIterations - 1 {
+		for _, i := range r.coupled {
+			solvePosition(&contacts[i])
+		}
+	}
+}
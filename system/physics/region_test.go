@@ -0,0 +1,161 @@
+package physics
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/anthdm/hollywood/actor"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+var sliderPID = actor.NewPID("local", "slider")
+
+// runRegions simulates boxes stacked on both sides of a region border, a box
+// sliding across it and a pendulum, sharing the steps between a number of workers
+func runRegions(workers, steps int) *Physics {
+	p := newTestPhysics()
+	p.workers = newWorkerPool(workers)
+	defer p.workers.stop()
+
+	for i := 0; i < 108; i++ {
+		x := regionSize - 3 + float64(i%6)*1.1
+		p.Register(actor.NewPID("local", fmt.Sprintf("box_%d", i)), EntityRigidBody{
+			Position:    mgl64.Vec3{x, 0.5 + float64(i/36)*1.05, float64(i/6%6) * 1.1},
+			Scale:       mgl64.Vec3{1, 1, 1},
+			Orientation: mgl64.QuatRotate(float64(i)*0.1, mgl64.Vec3{0, 1, 0}),
+		})
+	}
+	p.Register(sliderPID, EntityRigidBody{
+		Position: mgl64.Vec3{regionSize - 1, 0.5, -4},
+		Velocity: mgl64.Vec3{8, 0, 0},
+		Scale:    mgl64.Vec3{1, 1, 1},
+		Material: &IceMaterial,
+	})
+
+	pivot, bob := actor.NewPID("local", "pivot"), actor.NewPID("local", "bob")
+	p.Register(pivot, EntityRigidBody{Position: mgl64.Vec3{regionSize, 8, -8}, Scale: mgl64.Vec3{0.2, 0.2, 0.2}, BodyType: BodyStatic})
+	p.Register(bob, EntityRigidBody{Position: mgl64.Vec3{regionSize + 2, 8, -8}, Scale: mgl64.Vec3{0.5, 0.5, 0.5}})
+	p.CreateJoint("rope", Joint{Type: JointDistance, A: pivot, B: bob, AnchorA: mgl64.Vec3{regionSize, 8, -8}, AnchorB: mgl64.Vec3{regionSize + 2, 8, -8}})
+
+	for i := 0; i < steps; i++ {
+		p.Step(FixedDeltaTime)
+	}
+	return p
+}
+
+func TestRegionsSimulateTheSameWhateverTheWorkers(t *testing.T) {
+	serial := runRegions(1, 200)
+	parallel := runRegions(4, 200)
+
+	if serial.StateHash() != parallel.StateHash() {
+		t.Error("the state should not depend on the number of workers")
+	}
+	if len(serial.regions) < 2 {
+		t.Errorf("bodies should spread over several regions, got %d", len(serial.regions))
+	}
+
+	// The slider was handed over to the next region and kept going
+	slider := serial.entities[sliderPID]
+	if slider.Position.X() < regionSize+1 || slider.region != serial.regionAt(slider.Position, serialRegion) {
+		t.Errorf("slider should move into the next region, got %v in region %d", slider.Position, slider.region)
+	}
+
+	// Stacks leaning across the border stay on top of each other
+	for _, b := range serial.bodies {
+		if b.Position.Y() < 0.4 {
+			t.Errorf("%s sank through the floor, got %v", b.pid.ID, b.Position)
+		}
+	}
+}
+
+func TestWorkerPoolStartsWhenWorkIsShared(t *testing.T) {
+	w := newWorkerPool(4)
+	defer w.stop()
+
+	sum := 0
+	w.run(minBatch, minBatch, func(_, start, end int) { sum += end - start })
+	if sum != minBatch || w.jobs != nil {
+		t.Error("a single batch should run inline without starting the workers")
+	}
+
+	var batches [8]int
+	w.run(8*minBatch, minBatch, func(index, start, end int) { batches[index] = end - start })
+	if w.jobs == nil {
+		t.Fatal("several batches should start the workers")
+	}
+	for i, size := range batches {
+		if size != minBatch {
+			t.Errorf("batch %d should hold %d items, got %d", i, minBatch, size)
+		}
+	}
+
+	w.stop()
+	if w.jobs != nil || w.size != 1 {
+		t.Error("a stopped pool should run everything inline")
+	}
+}
+
+func TestRegionShortcutMatchesPassByPass(t *testing.T) {
+	// A box slides across the border into a crate resting on the other side,
+	// so some steps have contacts crossing regions and others do not. The
+	// second world also swings a pendulum far away, whose joint makes it solve
+	// every step pass by pass.
+	slider, crate := actor.NewPID("local", "slider"), actor.NewPID("local", "crate")
+	pivot, bob := actor.NewPID("local", "pivot"), actor.NewPID("local", "bob")
+	stack := make([]*actor.PID, 4)
+	for i := range stack {
+		stack[i] = actor.NewPID("local", fmt.Sprintf("stack_%d", i))
+	}
+	worlds := [2]*Physics{newTestPhysics(), newTestPhysics()}
+	for _, p := range worlds {
+		p.Register(slider, EntityRigidBody{Position: mgl64.Vec3{regionSize - 1.5, 0.5, 0}, Velocity: mgl64.Vec3{6, 0, 0}, Scale: mgl64.Vec3{1, 1, 1}, Material: &IceMaterial})
+		p.Register(crate, EntityRigidBody{Position: mgl64.Vec3{regionSize + 0.8, 0.5, 0.3}, Scale: mgl64.Vec3{1, 1, 1}})
+		for i, pid := range stack {
+			p.Register(pid, EntityRigidBody{
+				Position: mgl64.Vec3{float64(i/2) * regionSize * 2, 0.5 + float64(i%2)*1.05, 8},
+				Scale:    mgl64.Vec3{1, 1, 1},
+			})
+		}
+	}
+	passes := worlds[1]
+	passes.Register(pivot, EntityRigidBody{Position: mgl64.Vec3{-100, 20, -100}, Scale: mgl64.Vec3{0.2, 0.2, 0.2}, BodyType: BodyStatic})
+	passes.Register(bob, EntityRigidBody{Position: mgl64.Vec3{-98, 20, -100}, Scale: mgl64.Vec3{0.5, 0.5, 0.5}})
+	passes.CreateJoint("rope", Joint{Type: JointDistance, A: pivot, B: bob, AnchorA: mgl64.Vec3{-100, 20, -100}, AnchorB: mgl64.Vec3{-98, 20, -100}})
+
+	crossing := 0
+	for step := 0; step < 256; step++ {
+		for _, p := range worlds {
+			p.Step(FixedDeltaTime)
+		}
+		if len(worlds[0].crossContacts) > 0 {
+			crossing++
+		}
+
+		for pid, b := range worlds[0].entities {
+			other := passes.entities[pid]
+			if b.Position != other.Position || b.Velocity != other.Velocity || b.Orientation != other.Orientation {
+				t.Fatalf("step %d: %s at %v moving %v with the shortcut, at %v moving %v pass by pass", step, pid.ID, b.Position, b.Velocity, other.Position, other.Velocity)
+			}
+		}
+	}
+	if crossing == 0 || crossing == 256 {
+		t.Errorf("contacts should cross regions during some of the steps only, got %d of 256", crossing)
+	}
+}
+
+// BenchmarkStep10kWorkers measures a tick of 10k bodies on a single worker and
+// spread over several. More workers than CPUs only adds scheduling.
+func BenchmarkStep10kWorkers(b *testing.B) {
+	for _, workers := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			p := newBenchmarkWorld(b, 10_000)
+			p.workers = newWorkerPool(workers)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				p.Step(1.0 / 256)
+			}
+			b.ReportMetric(float64(b.N)/b.Elapsed().Seconds(), "ticks/s")
+		})
+	}
+}
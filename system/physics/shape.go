@@ -0,0 +1,452 @@
+package physics
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// ShapeType selects the collision shape of a body
+type ShapeType int
+
+const (
+	// ShapeBox is the collision box sized from the model bounds and the scale
+	ShapeBox ShapeType = iota
+	// ShapeSphere is a ball around the center of the body
+	ShapeSphere
+	// ShapeCapsule is a segment along the local Y axis grown by a radius
+	ShapeCapsule
+	// ShapeConvexHull is the smallest convex shape around a set of points
+	ShapeConvexHull
+	// ShapeTriangleMesh is a hollow surface made of triangles. It only suits
+	// level geometry, so bodies using it are always static.
+	ShapeTriangleMesh
+	// ShapeHeightfield is terrain, taking the place of the floor plane. Bodies
+	// using it are always static.
+	ShapeHeightfield
+)
+
+// Shape describes the collision shape of a body. Radius and HalfHeight are in
+// world units, zero fits them inside the collision box of the body. Points and
+// Triangles are in model space and scaled with the body like its model.
+// Raycasts and shape casts hit the shape itself, triangle by triangle on meshes.
+type Shape struct {
+	Type        ShapeType
+	Radius      float64         // Radius of spheres and capsules
+	HalfHeight  float64         // Half length of the segment of capsules, not counting the rounded ends
+	Points      []mgl64.Vec3    // Vertices of convex hulls
+	Triangles   [][3]mgl64.Vec3 // Triangles of triangle meshes
+	Heightfield *Heightfield    // Heights of terrain
+}
+
+// SphereShape returns a sphere of the given radius, zero fits the collision box
+func SphereShape(radius float64) *Shape {
+	return &Shape{Type: ShapeSphere, Radius: radius}
+}
+
+// CapsuleShape returns an upright capsule, zero sizes fit the collision box
+func CapsuleShape(radius, halfHeight float64) *Shape {
+	return &Shape{Type: ShapeCapsule, Radius: radius, HalfHeight: halfHeight}
+}
+
+// ConvexHullShape returns the convex hull around a set of points, usually the
+// vertices of a model. Repeated points are only kept once.
+func ConvexHullShape(points []mgl64.Vec3) *Shape {
+	seen := make(map[mgl64.Vec3]bool, len(points))
+	unique := make([]mgl64.Vec3, 0, len(points))
+	for _, point := range points {
+		if !seen[point] {
+			seen[point] = true
+			unique = append(unique, point)
+		}
+	}
+	return &Shape{Type: ShapeConvexHull, Points: unique}
+}
+
+// TriangleMeshShape returns a triangle mesh, usually the triangles of a model
+func TriangleMeshShape(triangles [][3]mgl64.Vec3) *Shape {
+	return &Shape{Type: ShapeTriangleMesh, Triangles: triangles}
+}
+
+// collisionShape is the shape of a body resolved against its size and scale
+type collisionShape struct {
+	kind        ShapeType
+	radius      float64
+	halfHeight  float64
+	points      []mgl64.Vec3  // Scaled convex hull vertices in local space
+	mesh        *triangleMesh // Triangle mesh in world space
+	heightfield *Heightfield  // Terrain centered on the body
+}
+
+// setShape resolves the collision shape of a body. It needs the collision box,
+// so it runs after the half extents are known. Characters always collide as
+// capsules, so it also runs after the character controller is attached.
+func (b *body) setShape(spec *Shape) {
+	b.shape = collisionShape{}
+	if b.character != nil && (spec == nil || spec.Type != ShapeCapsule) {
+		spec = CapsuleShape(0, 0)
+	}
+	if spec == nil {
+		return
+	}
+
+	b.shape.kind = spec.Type
+	fit := math.Min(b.halfExtents.X(), math.Min(b.halfExtents.Y(), b.halfExtents.Z()))
+	switch spec.Type {
+	case ShapeSphere:
+		b.shape.radius = spec.Radius
+		if b.shape.radius <= 0 {
+			b.shape.radius = fit
+		}
+	case ShapeCapsule:
+		b.shape.radius = spec.Radius
+		if b.shape.radius <= 0 {
+			b.shape.radius = math.Min(b.halfExtents.X(), b.halfExtents.Z())
+		}
+		b.shape.halfHeight = spec.HalfHeight
+		if b.shape.halfHeight <= 0 {
+			b.shape.halfHeight = math.Max(b.halfExtents.Y()-b.shape.radius, 0)
+		}
+	case ShapeConvexHull:
+		b.shape.points = make([]mgl64.Vec3, len(spec.Points))
+		for i, point := range spec.Points {
+			b.shape.points[i] = mulVec(point, b.Scale)
+		}
+	case ShapeTriangleMesh:
+		b.BodyType = BodyStatic
+		b.shape.mesh = newTriangleMesh(spec.Triangles, b.Position, normalizeOrientation(b.Orientation).Mat4().Mat3(), b.Scale)
+	case ShapeHeightfield:
+		if spec.Heightfield.empty() {
+			b.shape.kind = ShapeBox
+			break
+		}
+		b.BodyType = BodyStatic
+		b.shape.heightfield = spec.Heightfield
+	}
+}
+
+// shapeExtents returns the half extents of the axis-aligned box around a body
+// that is not a box
+func (b *body) shapeExtents() mgl64.Vec3 {
+	radius := mgl64.Vec3{b.shape.radius, b.shape.radius, b.shape.radius}
+	switch b.shape.kind {
+	case ShapeCapsule:
+		axis := b.rotation.Col(1)
+		return mgl64.Vec3{math.Abs(axis.X()), math.Abs(axis.Y()), math.Abs(axis.Z())}.Mul(b.shape.halfHeight).Add(radius)
+	case ShapeConvexHull:
+		var extents mgl64.Vec3
+		for _, point := range b.shape.points {
+			offset := b.rotation.Mul3x1(point)
+			for axis := 0; axis < 3; axis++ {
+				extents[axis] = math.Max(extents[axis], math.Abs(offset[axis]))
+			}
+		}
+		return extents
+	case ShapeTriangleMesh:
+		return b.shape.mesh.extents
+	case ShapeHeightfield:
+		return b.shape.heightfield.extents()
+	}
+	return radius
+}
+
+// updateShapeInertia sets the inverse inertia of spheres and capsules, other
+// shapes use the inertia of their collision box
+func (b *body) updateShapeInertia() bool {
+	r, h := b.shape.radius, b.shape.halfHeight
+	var inertia mgl64.Vec3
+	switch b.shape.kind {
+	case ShapeSphere:
+		// Solid ball: I = 2/5 * m * r²
+		i := 0.4 * b.Mass * r * r
+		inertia = mgl64.Vec3{i, i, i}
+	case ShapeCapsule:
+		// Approximated by a solid cylinder spanning the whole capsule
+		length := 2 * (h + r)
+		across := b.Mass / 12 * (3*r*r + length*length)
+		inertia = mgl64.Vec3{across, b.Mass / 2 * r * r, across}
+	default:
+		return false
+	}
+
+	for axis := range inertia {
+		if inertia[axis] > 0 {
+			b.inverseInertia[axis] = 1 / inertia[axis]
+		}
+	}
+	return true
+}
+
+// convex returns the shape of a body in world space as seen by GJK and EPA
+func (b *body) convex() convex {
+	shape := convex{position: b.Position, rotation: b.rotation, radius: b.shape.radius}
+	switch b.shape.kind {
+	case ShapeBox:
+		shape.half = b.halfExtents
+	case ShapeCapsule:
+		shape.half = mgl64.Vec3{0, b.shape.halfHeight, 0}
+	case ShapeConvexHull:
+		shape.points = b.shape.points
+	}
+	return shape
+}
+
+// convex is a convex shape in world space: a core point, segment, box or
+// polytope grown by a radius in every direction. Spheres are points and
+// capsules are segments, which lets GJK find their exact distance.
+type convex struct {
+	position mgl64.Vec3
+	rotation mgl64.Mat3
+	half     mgl64.Vec3   // Half extents of the core box, used when there are no points
+	points   []mgl64.Vec3 // Core vertices in local space
+	radius   float64
+}
+
+// coreSupport returns the point of the core furthest along a direction
+func (s *convex) coreSupport(direction mgl64.Vec3) mgl64.Vec3 {
+	local := s.rotation.Transpose().Mul3x1(direction)
+	if s.points == nil {
+		var corner mgl64.Vec3
+		for axis := 0; axis < 3; axis++ {
+			corner[axis] = s.half[axis]
+			if local[axis] < 0 {
+				corner[axis] = -s.half[axis]
+			}
+		}
+		return s.position.Add(s.rotation.Mul3x1(corner))
+	}
+
+	best, furthest := 0, math.Inf(-1)
+	for i, point := range s.points {
+		if distance := point.Dot(local); distance > furthest {
+			best, furthest = i, distance
+		}
+	}
+	return s.position.Add(s.rotation.Mul3x1(s.points[best]))
+}
+
+// support returns the point of the shape furthest along a direction
+func (s *convex) support(direction mgl64.Vec3) mgl64.Vec3 {
+	point := s.coreSupport(direction)
+	if length := direction.Len(); s.radius > 0 && length > 0 {
+		point = point.Add(direction.Mul(s.radius / length))
+	}
+	return point
+}
+
+// feature returns the vertices of the shape within manifoldTolerance of its
+// furthest point along a unit direction: a face, an edge or a single vertex.
+// Rounded shapes touch with a single point of their surface per core vertex.
+func (s *convex) feature(direction mgl64.Vec3, vertices []mgl64.Vec3) []mgl64.Vec3 {
+	vertices = vertices[:0]
+	if s.points == nil {
+		// The corners of the box, without repeating flat axes
+		var axes []int
+		for axis := 0; axis < 3; axis++ {
+			if s.half[axis] > 0 {
+				axes = append(axes, axis)
+			}
+		}
+		for i := 0; i < 1<<len(axes); i++ {
+			var corner mgl64.Vec3
+			for bit, axis := range axes {
+				corner[axis] = -s.half[axis]
+				if i&(1<<bit) != 0 {
+					corner[axis] = s.half[axis]
+				}
+			}
+			vertices = append(vertices, s.position.Add(s.rotation.Mul3x1(corner)))
+		}
+	} else {
+		for _, point := range s.points {
+			vertices = append(vertices, s.position.Add(s.rotation.Mul3x1(point)))
+		}
+	}
+
+	furthest := math.Inf(-1)
+	for _, vertex := range vertices {
+		furthest = math.Max(furthest, vertex.Dot(direction))
+	}
+	kept := vertices[:0]
+	for _, vertex := range vertices {
+		if vertex.Dot(direction) >= furthest-manifoldTolerance {
+			kept = append(kept, vertex.Add(direction.Mul(s.radius)))
+		}
+	}
+	return kept
+}
+
+// convexSeparation returns the direction from a towards b and the depth of the
+// overlap between two convex shapes. Cores closer than their radii are
+// measured exactly by GJK, overlapping cores are measured by EPA.
+func convexSeparation(a, b *convex) (mgl64.Vec3, float64, bool) {
+	result := gjk(a, b)
+	normal, depth := result.normal, -result.distance
+	if result.overlap {
+		normal, depth = epa(a, b, result.simplex)
+	}
+
+	depth += a.radius + b.radius
+	if depth <= 0 {
+		return mgl64.Vec3{}, 0, false
+	}
+	return normal, depth, true
+}
+
+// shapeSeparation is separation for pairs of bodies that are not both boxes
+func shapeSeparation(a, b *body) (mgl64.Vec3, float64, bool) {
+	shapeA, shapeB := a.convex(), b.convex()
+	return convexSeparation(&shapeA, &shapeB)
+}
+
+// addClippedFeatures builds the manifold of two convex shapes from the features
+// they touch with. The feature with the most vertices is the reference and the
+// other one is clipped against its sides, keeping the points that are behind
+// its surface, halfway through the overlap.
+func (c *contact) addClippedFeatures(a, b *convex) {
+	var bufferA, bufferB [64]mgl64.Vec3
+	featureA := a.feature(c.normal, bufferA[:0])
+	featureB := b.feature(c.normal.Mul(-1), bufferB[:0])
+
+	reference, incident, outward := featureA, featureB, c.normal
+	if len(featureB) > len(featureA) {
+		reference, incident, outward = featureB, featureA, c.normal.Mul(-1)
+	}
+	reference = orderPolygon(reference, outward)
+	incident = orderPolygon(incident, outward)
+
+	switch len(reference) {
+	case 1:
+	case 2:
+		along := reference[1].Sub(reference[0])
+		incident = clipPolygon(incident, reference[0], along.Mul(-1))
+		incident = clipPolygon(incident, reference[1], along)
+	default:
+		for i := range reference {
+			from, to := reference[i], reference[(i+1)%len(reference)]
+			side := to.Sub(from).Cross(outward)
+			if side.Dot(reference[(i+2)%len(reference)].Sub(from)) > 0 {
+				side = side.Mul(-1)
+			}
+			incident = clipPolygon(incident, from, side)
+		}
+	}
+
+	surface := reference[0].Dot(outward)
+	step := 1
+	if len(incident) > maxManifoldPoints {
+		step = (len(incident) + maxManifoldPoints - 1) / maxManifoldPoints
+	}
+	for i := 0; i < len(incident); i += step {
+		point := incident[i]
+		if depth := surface - point.Dot(outward); depth >= -manifoldTolerance {
+			c.addPoint(point.Add(outward.Mul(depth / 2)))
+		}
+	}
+
+	// Clipping can leave nothing when the features barely touch
+	if c.pointCount == 0 {
+		c.addPoint(a.support(c.normal).Add(b.support(c.normal.Mul(-1))).Mul(0.5))
+	}
+}
+
+// addShapeFeature uses the feature of the shape of a resting on the ground
+func (c *contact) addShapeFeature() {
+	var buffer [64]mgl64.Vec3
+	shape := c.a.convex()
+	feature := orderPolygon(shape.feature(c.normal, buffer[:0]), c.normal)
+
+	step := 1
+	if len(feature) > maxManifoldPoints {
+		step = (len(feature) + maxManifoldPoints - 1) / maxManifoldPoints
+	}
+	for i := 0; i < len(feature); i += step {
+		c.addPoint(feature[i])
+	}
+}
+
+// orderPolygon sorts the vertices of a flat feature around its center, so
+// consecutive vertices share an edge. Vertices along a line are an edge and
+// are reduced to its ends.
+func orderPolygon(vertices []mgl64.Vec3, normal mgl64.Vec3) []mgl64.Vec3 {
+	if len(vertices) < 3 {
+		return vertices
+	}
+
+	var center mgl64.Vec3
+	for _, vertex := range vertices {
+		center = center.Add(vertex)
+	}
+	center = center.Mul(1 / float64(len(vertices)))
+
+	tangents := tangentBasis(normal)
+	angle := func(vertex mgl64.Vec3) float64 {
+		offset := vertex.Sub(center)
+		return math.Atan2(offset.Dot(tangents[1]), offset.Dot(tangents[0]))
+	}
+	// Insertion sort, features are small
+	for i := 1; i < len(vertices); i++ {
+		for j := i; j > 0 && angle(vertices[j]) < angle(vertices[j-1]); j-- {
+			vertices[j], vertices[j-1] = vertices[j-1], vertices[j]
+		}
+	}
+
+	var area mgl64.Vec3
+	for i, vertex := range vertices {
+		area = area.Add(vertex.Sub(center).Cross(vertices[(i+1)%len(vertices)].Sub(center)))
+	}
+	if area.Len() > 1e-9 {
+		return vertices
+	}
+	first := furthestFrom(vertices, vertices[0])
+	second := furthestFrom(vertices, first)
+	return append(vertices[:0], first, second)
+}
+
+// furthestFrom returns the vertex furthest from a point
+func furthestFrom(vertices []mgl64.Vec3, point mgl64.Vec3) mgl64.Vec3 {
+	furthest, distance := point, 0.0
+	for _, vertex := range vertices {
+		if d := vertex.Sub(point).LenSqr(); d > distance {
+			furthest, distance = vertex, d
+		}
+	}
+	return furthest
+}
+
+// clipPolygon keeps the part of a polygon behind the plane through point with
+// the given outward normal. Points and segments are clipped like polygons.
+func clipPolygon(polygon []mgl64.Vec3, point, normal mgl64.Vec3) []mgl64.Vec3 {
+	if len(polygon) == 1 {
+		if polygon[0].Sub(point).Dot(normal) > 0 {
+			return polygon[:0]
+		}
+		return polygon
+	}
+
+	clipped := make([]mgl64.Vec3, 0, len(polygon)+1)
+	edges := len(polygon)
+	if edges == 2 {
+		edges = 1
+	}
+	for i := 0; i < edges; i++ {
+		from, to := polygon[i], polygon[(i+1)%len(polygon)]
+		fromDistance, toDistance := from.Sub(point).Dot(normal), to.Sub(point).Dot(normal)
+		if fromDistance <= 0 {
+			clipped = append(clipped, from)
+		}
+		if fromDistance*toDistance < 0 {
+			t := fromDistance / (fromDistance - toDistance)
+			clipped = append(clipped, from.Add(to.Sub(from).Mul(t)))
+		}
+	}
+	if len(polygon) == 2 && polygon[1].Sub(point).Dot(normal) <= 0 {
+		clipped = append(clipped, polygon[1])
+	}
+	return clipped
+}
+
+// mulVec multiplies two vectors component by component
+func mulVec(a, b mgl64.Vec3) mgl64.Vec3 {
+	return mgl64.Vec3{a.X() * b.X(), a.Y() * b.Y(), a.Z() * b.Z()}
+}
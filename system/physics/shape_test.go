@@ -0,0 +1,167 @@
+package physics
+
+import (
+	"math"
+	"testing"
+
+	"github.com/anthdm/hollywood/actor"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// unitCube returns the corners of a cube of size 1 centered on the origin
+func unitCube() []mgl64.Vec3 {
+	var points []mgl64.Vec3
+	for i := 0; i < 8; i++ {
+		points = append(points, mgl64.Vec3{float64(i&1) - 0.5, float64(i>>1&1) - 0.5, float64(i>>2&1) - 0.5})
+	}
+	return points
+}
+
+// testShapes are shapes spanning from -0.5 to 0.5 along Y, so two of them
+// stacked along Y overlap by the same depth whatever they are
+var testShapes = map[string]*Shape{
+	"box":     nil,
+	"sphere":  SphereShape(0.5),
+	"capsule": CapsuleShape(0.25, 0.25),
+	"hull":    ConvexHullShape(unitCube()),
+}
+
+// newShapeBody registers a dynamic unit sized body with a shape
+func newShapeBody(p *Physics, name string, shape *Shape, position mgl64.Vec3) *body {
+	pid := actor.NewPID("local", name)
+	p.Register(pid, EntityRigidBody{Position: position, Scale: mgl64.Vec3{1, 1, 1}, Shape: shape})
+	return p.entities[pid]
+}
+
+func TestSeparationBetweenEveryPairOfShapes(t *testing.T) {
+	for nameA, shapeA := range testShapes {
+		for nameB, shapeB := range testShapes {
+			// Shallow overlaps are measured by GJK between rounded cores and deep
+			// ones by EPA
+			for _, overlap := range []float64{0.1, 0.45} {
+				p := newTestPhysics()
+				a := newShapeBody(p, "a", shapeA, mgl64.Vec3{0, 5, 0})
+				b := newShapeBody(p, "b", shapeB, mgl64.Vec3{0, 6 - overlap, 0})
+
+				normal, depth, ok := separation(a, b)
+				if !ok || math.Abs(depth-overlap) > 1e-3 || normal.Y() < 0.999 {
+					t.Errorf("%s against %s overlapping by %.1f: got normal %v and depth %f", nameA, nameB, overlap, normal, depth)
+				}
+				c, _ := computeContact(a, b)
+				c.buildManifold()
+				if c.pointCount == 0 {
+					t.Errorf("%s against %s overlapping by %.1f: no manifold points", nameA, nameB, overlap)
+				}
+			}
+
+			p := newTestPhysics()
+			a := newShapeBody(p, "a", shapeA, mgl64.Vec3{0, 5, 0})
+			b := newShapeBody(p, "b", shapeB, mgl64.Vec3{0.2, 6.05, 0})
+			if _, _, ok := separation(a, b); ok {
+				t.Errorf("%s against %s apart by 0.05 should not touch", nameA, nameB)
+			}
+		}
+	}
+}
+
+func TestRoundShapesTouchThroughTheirSurface(t *testing.T) {
+	p := newTestPhysics()
+
+	// Spheres meeting on the diagonal push along it
+	a := newShapeBody(p, "a", SphereShape(0.5), mgl64.Vec3{0, 5, 0})
+	b := newShapeBody(p, "b", SphereShape(0.5), mgl64.Vec3{0.6, 5.6, 0})
+	normal, depth, ok := separation(a, b)
+	if expected := 1 - 0.6*math.Sqrt2; !ok || math.Abs(depth-expected) > 1e-6 || normal.Sub(mgl64.Vec3{1, 1, 0}.Normalize()).Len() > 1e-6 {
+		t.Errorf("spheres should touch along the diagonal by %f, got normal %v and depth %f", expected, normal, depth)
+	}
+
+	// A sphere next to the corner of a box is not touching it, even though
+	// their boxes overlap
+	box := newShapeBody(p, "box", nil, mgl64.Vec3{10, 5, 0})
+	ball := newShapeBody(p, "ball", SphereShape(0.5), mgl64.Vec3{10.9, 5.9, 0})
+	if _, _, ok := separation(box, ball); ok {
+		t.Error("sphere beyond the corner of a box should not touch it")
+	}
+
+	// Capsules deep into each other end to end are pushed apart sideways
+	bottom := newShapeBody(p, "bottom", CapsuleShape(0.25, 0.25), mgl64.Vec3{20, 5, 0})
+	top := newShapeBody(p, "top", CapsuleShape(0.25, 0.25), mgl64.Vec3{20, 5.3, 0})
+	normal, depth, ok = separation(bottom, top)
+	if !ok || math.Abs(depth-0.5) > 1e-6 || math.Abs(normal.Y()) > 1e-6 {
+		t.Errorf("capsules should be pushed apart sideways by 0.5, got normal %v and depth %f", normal, depth)
+	}
+}
+
+func TestTriangleMeshTouchesEveryConvexShape(t *testing.T) {
+	floor := TriangleMeshShape([][3]mgl64.Vec3{
+		{{-2, 0, -2}, {2, 0, -2}, {2, 0, 2}},
+		{{-2, 0, -2}, {2, 0, 2}, {-2, 0, 2}},
+	})
+
+	for name, shape := range testShapes {
+		p := newTestPhysics()
+		mesh := actor.NewPID("local", "mesh")
+		p.Register(mesh, EntityRigidBody{Position: mgl64.Vec3{0, 3, 0}, Scale: mgl64.Vec3{1, 1, 1}, Shape: floor, BodyType: BodyDynamic})
+		if p.entities[mesh].BodyType != BodyStatic {
+			t.Fatal("triangle meshes should always be static")
+		}
+
+		a := newShapeBody(p, name, shape, mgl64.Vec3{0.5, 3.4, 0.5})
+		var out narrowphase
+		p.testPair(a, p.entities[mesh], &out)
+		contacts := out.contacts
+		if len(contacts) == 0 {
+			t.Errorf("%s should touch the mesh", name)
+			continue
+		}
+		for _, c := range contacts {
+			if math.Abs(c.depth-0.1) > 1e-3 || c.normal.Y() > -0.999 || c.pointCount == 0 {
+				t.Errorf("%s against the mesh: got normal %v, depth %f and %d points", name, c.normal, c.depth, c.pointCount)
+			}
+		}
+	}
+}
+
+func TestShapesComeToRestOnTriangleMesh(t *testing.T) {
+	p := newTestPhysics()
+
+	// A raised floor of two triangles, turned so that its diagonal runs under the bodies
+	mesh := actor.NewPID("local", "mesh")
+	p.Register(mesh, EntityRigidBody{
+		Position:    mgl64.Vec3{0, 2, 0},
+		Scale:       mgl64.Vec3{10, 1, 10},
+		Orientation: mgl64.QuatRotate(math.Pi/4, mgl64.Vec3{0, 1, 0}),
+		Shape: TriangleMeshShape([][3]mgl64.Vec3{
+			{{-1, 0, -1}, {1, 0, -1}, {1, 0, 1}},
+			{{-1, 0, -1}, {1, 0, 1}, {-1, 0, 1}},
+		}),
+	})
+
+	lying := mgl64.QuatRotate(math.Pi/2, mgl64.Vec3{0, 0, 1})
+	expected := map[string]float64{"box": 2.5, "sphere": 2.5, "capsule": 2.25, "hull": 2.5}
+	bodies := make(map[string]*body)
+	x := -4.5
+	for name, shape := range testShapes {
+		pid := actor.NewPID("local", name)
+		entity := EntityRigidBody{Position: mgl64.Vec3{x, 4, 0}, Scale: mgl64.Vec3{1, 1, 1}, Shape: shape}
+		if name == "capsule" {
+			entity.Orientation = lying
+		}
+		p.Register(pid, entity)
+		bodies[name] = p.entities[pid]
+		x += 3
+	}
+
+	for i := 0; i < 256*3; i++ {
+		p.Step(FixedDeltaTime)
+	}
+
+	for name, b := range bodies {
+		if y := b.Position.Y(); math.Abs(y-expected[name]) > 0.02 {
+			t.Errorf("%s should rest on the mesh at y=%.2f, got %v", name, expected[name], b.Position)
+		}
+		if b.Velocity.Len() > 0.05 {
+			t.Errorf("%s should have stopped, velocity %v", name, b.Velocity)
+		}
+	}
+}
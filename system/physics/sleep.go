@@ -0,0 +1,147 @@
+package physics
+
+import (
+	"slices"
+
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+const (
+	// sleepLinearThreshold is the speed under which a body counts as resting
+	sleepLinearThreshold = 0.35
+
+	// sleepAngularThreshold is the angular speed, in radians per second, under
+	// which a body counts as resting
+	sleepAngularThreshold = 0.2
+
+	// timeToSleep is how long, in seconds, every body of an island must have
+	// been resting before the island is put to sleep
+	timeToSleep = 0.5
+)
+
+// updateSleep groups the dynamic bodies into islands of bodies touching each
+// other and puts an island to sleep once all of its bodies have been resting
+// for timeToSleep. Bodies of an island always sleep and wake together, so a
+// stack never loses one of its supports while the rest of it is simulated.
+func (p *Physics) updateSleep(contacts []contact, deltaTime float64) {
+	parent := p.islandParents[:0]
+	for i, b := range p.active {
+		b.islandIndex = int32(i)
+		parent = append(parent, int32(i))
+	}
+	p.islandParents = parent
+
+	// Static and kinematic bodies do not join islands, otherwise everything
+	// resting on the same platform would form a single island. Jointed bodies
+	// always share one.
+	for i := range contacts {
+		c := &contacts[i]
+		if c.a.InverseMass == 0 || c.b.InverseMass == 0 {
+			continue
+		}
+		union(parent, c.a.islandIndex, c.b.islandIndex)
+	}
+	for _, j := range p.stepJoints {
+		if j.a.InverseMass == 0 || j.b.InverseMass == 0 {
+			continue
+		}
+		union(parent, j.a.islandIndex, j.b.islandIndex)
+	}
+
+	// An island stays awake while any of its bodies is moving
+	awake := p.islandAwake[:0]
+	for range p.active {
+		awake = append(awake, false)
+	}
+	p.islandAwake = awake
+
+	for _, b := range p.active {
+		root := find(parent, b.islandIndex)
+		if b.BodyType != BodyDynamic {
+			awake[root] = true
+			continue
+		}
+
+		if b.Velocity.Len() < sleepLinearThreshold && b.AngularVelocity.Len() < sleepAngularThreshold {
+			b.sleepTimer += deltaTime
+		} else {
+			b.sleepTimer = 0
+		}
+		if b.sleepTimer < timeToSleep {
+			awake[root] = true
+		}
+	}
+
+	// Roots are kept in the order they are found so that bodies always fall
+	// asleep in the same order
+	var roots []int32
+	islands := make(map[int32][]*body)
+	for _, b := range p.active {
+		root := find(parent, b.islandIndex)
+		if awake[root] {
+			continue
+		}
+		if _, ok := islands[root]; !ok {
+			roots = append(roots, root)
+		}
+		islands[root] = append(islands[root], b)
+	}
+	if len(roots) == 0 {
+		return
+	}
+
+	for _, root := range roots {
+		island := islands[root]
+		for _, b := range island {
+			p.removeProxy(b)
+			b.sleeping = true
+			b.island = island
+			b.Velocity = mgl64.Vec3{}
+			b.AngularVelocity = mgl64.Vec3{}
+			p.insertProxy(b)
+		}
+	}
+	p.active = slices.DeleteFunc(p.active, func(b *body) bool { return b.sleeping })
+}
+
+// wake puts a sleeping body back into the simulation together with the rest of its island
+func (p *Physics) wake(b *body) {
+	if !b.sleeping {
+		return
+	}
+
+	for _, member := range b.island {
+		p.removeProxy(member)
+		member.sleeping = false
+		member.sleepTimer = 0
+		member.island = nil
+		member.keepTransform()
+		p.insertProxy(member)
+		p.active = append(p.active, member)
+	}
+}
+
+// wakeTouched wakes the sleeping bodies touched by a moving body during the last contact search
+func (p *Physics) wakeTouched() {
+	for _, b := range p.touchedSleeping {
+		p.wake(b)
+	}
+	p.touchedSleeping = p.touchedSleeping[:0]
+}
+
+// find returns the root of an island, flattening the path on the way
+func find(parent []int32, i int32) int32 {
+	for parent[i] != i {
+		parent[i] = parent[parent[i]]
+		i = parent[i]
+	}
+	return i
+}
+
+// union merges the islands of two bodies
+func union(parent []int32, a, b int32) {
+	rootA, rootB := find(parent, a), find(parent, b)
+	if rootA != rootB {
+		parent[rootB] = rootA
+	}
+}
@@ -0,0 +1,63 @@
+package physics
+
+import (
+	"testing"
+
+	"github.com/anthdm/hollywood/actor"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+func TestRestingIslandSleepsAndWakesOnContact(t *testing.T) {
+	p := newTestPhysics()
+
+	bottom := actor.NewPID("local", "bottom")
+	top := actor.NewPID("local", "top")
+	p.Register(bottom, EntityRigidBody{Position: mgl64.Vec3{0, 0.5, 0}, Scale: mgl64.Vec3{1, 1, 1}})
+	p.Register(top, EntityRigidBody{Position: mgl64.Vec3{0, 1.6, 0}, Scale: mgl64.Vec3{1, 1, 1}})
+
+	for i := 0; i < 256*2; i++ {
+		p.Step(FixedDeltaTime)
+	}
+
+	if !p.entities[bottom].sleeping || !p.entities[top].sleeping {
+		t.Fatal("a resting stack should fall asleep")
+	}
+	if len(p.active) != 0 {
+		t.Fatalf("sleeping bodies should not be stepped, %d still active", len(p.active))
+	}
+	if len(p.entities[top].island) != 2 {
+		t.Errorf("the stack should sleep as a single island, got %d bodies", len(p.entities[top].island))
+	}
+
+	// A cube dropped on the stack wakes the whole island
+	falling := actor.NewPID("local", "falling")
+	p.Register(falling, EntityRigidBody{Position: mgl64.Vec3{0, 4, 0}, Scale: mgl64.Vec3{1, 1, 1}})
+	for i := 0; i < 256 && p.entities[bottom].sleeping; i++ {
+		p.Step(FixedDeltaTime)
+	}
+
+	if p.entities[bottom].sleeping || p.entities[top].sleeping {
+		t.Error("landing on the stack should wake every body of the island")
+	}
+}
+
+func TestWakeReturnsBodyToSimulation(t *testing.T) {
+	p := newTestPhysics()
+
+	crate := actor.NewPID("local", "crate")
+	p.Register(crate, EntityRigidBody{Position: mgl64.Vec3{0, 0.5, 0}, Scale: mgl64.Vec3{1, 1, 1}})
+	for i := 0; i < 256*2; i++ {
+		p.Step(FixedDeltaTime)
+	}
+	if !p.entities[crate].sleeping {
+		t.Fatal("a resting crate should fall asleep")
+	}
+
+	p.wake(p.entities[crate])
+	p.entities[crate].Velocity = mgl64.Vec3{1, 0, 0}
+	p.Step(FixedDeltaTime)
+
+	if p.entities[crate].sleeping || p.entities[crate].Position.X() <= 0 {
+		t.Error("a woken crate should move again")
+	}
+}
@@ -0,0 +1,50 @@
+package physics
+
+import "github.com/anthdm/hollywood/actor"
+
+// trackedTrigger is a body overlapping a sensor, followed across ticks to report
+// when it enters and leaves the sensor
+type trackedTrigger struct {
+	sensor, other *body
+	touching      bool // Found during one of the steps of the current tick
+	started       bool // Not reported yet
+}
+
+// recordTrigger remembers that a body overlaps a sensor during the current tick
+func (p *Physics) recordTrigger(sensor, other *body) {
+	key := makePairKey(sensor, other)
+	tracked, ok := p.triggers[key]
+	if !ok {
+		tracked = &trackedTrigger{sensor: sensor, other: other, started: true}
+		p.triggers[key] = tracked
+	}
+	tracked.touching = true
+}
+
+// triggerEvents reports the bodies that entered or left a sensor during the
+// last tick, both to the owner of the sensor and to the overlapping body
+func (p *Physics) triggerEvents(send func(pid *actor.PID, event any)) {
+	for _, key := range pairOrder(p.triggers, p.deterministic) {
+		tracked := p.triggers[key]
+		// Sleeping bodies stay inside the sensors they fell asleep in
+		if !tracked.touching && tracked.sensor.dormant() && tracked.other.dormant() {
+			continue
+		}
+
+		trigger, other := tracked.sensor.pid, tracked.other.pid
+
+		switch {
+		case !tracked.touching:
+			send(trigger, EventTriggerExit{PID: trigger, Trigger: trigger, Other: other})
+			send(other, EventTriggerExit{PID: other, Trigger: trigger, Other: other})
+			delete(p.triggers, key)
+			continue
+		case tracked.started:
+			send(trigger, EventTriggerEnter{PID: trigger, Trigger: trigger, Other: other})
+			send(other, EventTriggerEnter{PID: other, Trigger: trigger, Other: other})
+			tracked.started = false
+		}
+
+		tracked.touching = false
+	}
+}
@@ -0,0 +1,45 @@
+package physics
+
+import (
+	"testing"
+
+	"github.com/anthdm/hollywood/actor"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+func TestSensorReportsEnterAndExitWithoutColliding(t *testing.T) {
+	p := newTestPhysics()
+
+	zone := actor.NewPID("local", "zone")
+	crate := actor.NewPID("local", "crate")
+	p.Register(zone, EntityRigidBody{Position: mgl64.Vec3{0, 4, 0}, Scale: mgl64.Vec3{4, 1, 4}, BodyType: BodyStatic, Sensor: true})
+	p.Register(crate, EntityRigidBody{Position: mgl64.Vec3{0, 6, 0}, Scale: mgl64.Vec3{1, 1, 1}})
+
+	var received []any
+	for i := 0; i < 256*5; i++ {
+		p.Advance(FixedDeltaTime)
+		p.triggerEvents(func(pid *actor.PID, event any) {
+			if pid == zone {
+				received = append(received, event)
+			}
+		})
+	}
+
+	if len(received) != 2 {
+		t.Fatalf("expected an enter and an exit event, got %v", received)
+	}
+	if enter, ok := received[0].(EventTriggerEnter); !ok || enter.Other != crate || enter.Trigger != zone {
+		t.Errorf("expected the crate to enter the zone first, got %+v", received[0])
+	}
+	if _, ok := received[1].(EventTriggerExit); !ok {
+		t.Errorf("expected the crate to leave the zone, got %+v", received[1])
+	}
+
+	// The crate falls straight through the sensor onto the floor
+	if y := p.entities[crate].Position.Y(); y > 0.55 {
+		t.Errorf("sensor should not stop the crate, got y=%f", y)
+	}
+	if len(p.contacts) != 0 {
+		t.Errorf("sensor overlaps should not produce contacts, got %d", len(p.contacts))
+	}
+}
@@ -2,12 +2,64 @@ package physics
 
 import "github.com/go-gl/mathgl/mgl64"
 
+// defaultMass is used for bodies registered without a positive mass
+const defaultMass = 1.0
+
+// BodyType decides how a body takes part in the simulation
+type BodyType int
+
+const (
+	// BodyDynamic bodies are fully simulated: gravity, forces and contacts move them
+	BodyDynamic BodyType = iota
+	// BodyStatic bodies never move and are skipped by integration entirely
+	BodyStatic
+	// BodyKinematic bodies are moved by their owner through their velocity. They
+	// ignore gravity and contacts but still push dynamic bodies out of the way.
+	BodyKinematic
+)
+
+// LayerDefault is the layer of bodies registered without one. Layers are bit
+// flags, so queries can match several of them with a single mask.
+const LayerDefault uint32 = 1
+
 type EntityRigidBody struct {
-	Position        mgl64.Vec3
-	Velocity        mgl64.Vec3
-	Scale           mgl64.Vec3
-	Rotation        mgl64.Vec3
-	AngularVelocity mgl64.Vec3
-	ModelName       string
-	EntityType      string // "player", "cube", "floor", etc.
+	BodyType            BodyType
+	Layer               uint32 // Zero uses LayerDefault
+	CollisionMask       uint32 // Layers the body collides with and its sensor detects, zero matches every layer
+	Sensor              bool   // Detects overlaps through trigger events without colliding
+	Position            mgl64.Vec3
+	Velocity            mgl64.Vec3 // In world units per second
+	Scale               mgl64.Vec3
+	Shape               *Shape               // Nil collides as the box given by the model bounds and the scale
+	Orientation         mgl64.Quat           // The zero quaternion is treated as no rotation
+	AngularVelocity     mgl64.Vec3           // World space, in radians per second
+	FixedRotation       bool                 // Contacts never rotate the body, used by characters
+	ContinuousCollision bool                 // Swept along its path every step so it cannot tunnel through thin geometry
+	Mass                float64              // Zero or negative masses are replaced by defaultMass on registration
+	GravityScale        *float64             // Multiplies the world gravity, nil uses 1
+	InverseMass         float64              // Derived from Mass by the physics system
+	Material            *PhysicsMaterial     // Nil uses DefaultMaterial
+	Character           *CharacterController // Moves the body with the character controller, making it kinematic
+	ForceField          *ForceField          // Pushes the bodies inside instead of colliding with them
+	Vehicle             *VehicleController   // Drives the body on raycast wheels
+	ModelName           string
+	EntityType          string // "player", "cube", "floor", etc.
+}
+
+// Interpolate blends the transform of the body towards next by alpha, where an
+// alpha of 0 returns this body's transform and 1 returns next's
+func (e EntityRigidBody) Interpolate(next EntityRigidBody, alpha float64) EntityRigidBody {
+	result := next
+	result.Position = e.Position.Add(next.Position.Sub(e.Position).Mul(alpha))
+	result.Orientation = mgl64.QuatSlerp(normalizeOrientation(e.Orientation), normalizeOrientation(next.Orientation), alpha)
+	return result
+}
+
+// normalizeOrientation returns a unit quaternion, mapping the zero quaternion of
+// bodies created without an orientation to the identity
+func normalizeOrientation(q mgl64.Quat) mgl64.Quat {
+	if q.Len() == 0 {
+		return mgl64.QuatIdent()
+	}
+	return q.Normalize()
 }
@@ -0,0 +1,229 @@
+package physics
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// Wheel is a wheel of a vehicle, held under the body by a raycast suspension.
+// Every step a ray is cast from the top of the suspension down along the body,
+// and the wheel touches the ground where the ray hits within its reach.
+type Wheel struct {
+	Position   mgl64.Vec3 // Top of the suspension in the local space of the body
+	Radius     float64
+	RestLength float64 // Length of the unloaded suspension, from Position to the center of the wheel
+	Stiffness  float64 // Spring force per unit of compression
+	Damping    float64 // Damper force per unit of compression speed
+	Steered    bool    // Turned by the steering input
+	Driven     bool    // Turned by the engine
+}
+
+// VehicleController turns a dynamic body into a vehicle rolling on raycast
+// wheels. The body only collides with its own shape, the wheels are rays that
+// push it up and grip the ground. Forward is the local Z axis of the body.
+type VehicleController struct {
+	Wheels               []Wheel
+	EngineTorque         float64 // Torque at full throttle, shared between the driven wheels
+	BrakeTorque          float64 // Torque of every wheel at full brake
+	MaxSteerAngle        float64 // Angle of the steered wheels at full steering, in radians
+	LongitudinalFriction float64 // Grip of the tires along their rolling direction, relative to their load
+	LateralFriction      float64 // Grip of the tires against sliding sideways, relative to their load
+	RollingResistance    float64 // Force slowing down rolling wheels, relative to their load
+	RollInfluence        float64 // How much the tires roll the body, from 0 pushing level with its center to 1 pushing at the ground
+}
+
+// DefaultVehicleController suits a car with a mass of 10 and half extents of
+// 1, 0.5 and 2, its wheels sticking out under the corners of the body
+var DefaultVehicleController = VehicleController{
+	Wheels: []Wheel{
+		{Position: mgl64.Vec3{0.9, -0.3, 1.4}, Radius: 0.4, RestLength: 0.5, Stiffness: 230, Damping: 20, Steered: true},
+		{Position: mgl64.Vec3{-0.9, -0.3, 1.4}, Radius: 0.4, RestLength: 0.5, Stiffness: 230, Damping: 20, Steered: true},
+		{Position: mgl64.Vec3{0.9, -0.3, -1.4}, Radius: 0.4, RestLength: 0.5, Stiffness: 230, Damping: 20, Driven: true},
+		{Position: mgl64.Vec3{-0.9, -0.3, -1.4}, Radius: 0.4, RestLength: 0.5, Stiffness: 230, Damping: 20, Driven: true},
+	},
+	EngineTorque:         24,
+	BrakeTorque:          15,
+	MaxSteerAngle:        0.5,
+	LongitudinalFriction: 1.0,
+	LateralFriction:      1.2,
+	RollingResistance:    0.02,
+	RollInfluence:        0.2,
+}
+
+// WheelState is where a wheel of a vehicle is after a tick
+type WheelState struct {
+	Position mgl64.Vec3 // Center of the wheel in world space
+	Steer    float64    // Steering angle, positive turning left
+	Grounded bool       // Touching the ground
+}
+
+// vehicle is the state of the controller of a body
+type vehicle struct {
+	VehicleController
+
+	throttle float64 // Engine input from -1 for full reverse to 1 for full throttle
+	steering float64 // Steering input from -1 for full right to 1 for full left
+	brake    float64 // Brake input from 0 to 1
+	wheels   []WheelState
+	hits     []wheelHit // Scratch buffer of the suspension rays of a step
+}
+
+// wheelHit is where the suspension ray of a wheel hit the ground and the
+// impulse of the wheel on the body
+type wheelHit struct {
+	distance float64
+	normal   mgl64.Vec3
+	ground   *body // Nil when the wheel is in the air
+	contact  mgl64.Vec3
+	impulse  mgl64.Vec3
+}
+
+// setVehicle attaches a vehicle controller to a body. Only dynamic bodies are
+// driven.
+func (b *body) setVehicle(settings *VehicleController) {
+	b.vehicle = nil
+	if settings == nil {
+		return
+	}
+
+	b.vehicle = &vehicle{
+		VehicleController: *settings,
+		wheels:            make([]WheelState, len(settings.Wheels)),
+		hits:              make([]wheelHit, len(settings.Wheels)),
+	}
+}
+
+// driveVehicles applies the suspension and tire forces of every moving
+// vehicle, before bodies are integrated
+func (p *Physics) driveVehicles(deltaTime float64) {
+	for _, b := range p.vehicles {
+		if b.BodyType == BodyDynamic && !b.sleeping {
+			p.driveVehicle(b, deltaTime)
+		}
+	}
+}
+
+// driveVehicle casts the suspension ray of every wheel of a vehicle. A wheel on
+// the ground pushes the body up with its spring and damper, and its tire pushes
+// it along the ground: forwards with the engine, backwards with the brake and
+// sideways against sliding, within the grip given by the load on the wheel.
+// Dynamic bodies driven over are pushed back the other way. The impulses of
+// all wheels are found from the state before any of them is applied, so that
+// the order of the wheels does not matter.
+func (p *Physics) driveVehicle(b *body, deltaTime float64) {
+	v := b.vehicle
+	up := b.rotation.Col(1)
+	down := up.Mul(-1)
+
+	// The mass of the vehicle is shared between the wheels on the ground
+	driven, grounded := 0, 0
+	for i, wheel := range v.Wheels {
+		if wheel.Driven {
+			driven++
+		}
+		mount := b.Position.Add(b.rotation.Mul3x1(wheel.Position))
+		v.hits[i] = p.castWheel(b, mount, down, wheel.RestLength+wheel.Radius)
+		if v.hits[i].ground != nil {
+			grounded++
+		}
+	}
+
+	for i, wheel := range v.Wheels {
+		hit, state := v.hits[i], &v.wheels[i]
+		mount := b.Position.Add(b.rotation.Mul3x1(wheel.Position))
+		*state = WheelState{Position: mount.Add(down.Mul(wheel.RestLength))}
+		if wheel.Steered {
+			state.Steer = v.steering * v.MaxSteerAngle
+		}
+		if hit.ground == nil {
+			continue
+		}
+		state.Position = mount.Add(down.Mul(hit.distance - wheel.Radius))
+		state.Grounded = true
+
+		// The spring pushes harder the more it is compressed, the damper
+		// resists the compression changing
+		contact := mount.Add(down.Mul(hit.distance))
+		r := contact.Sub(b.Position)
+		velocity := b.velocityAt(r)
+		if hit.ground.BodyType != BodyStatic {
+			velocity = velocity.Sub(hit.ground.velocityAt(contact.Sub(hit.ground.Position)))
+		}
+		compression := wheel.RestLength + wheel.Radius - hit.distance
+		load := wheel.Stiffness*compression - wheel.Damping*velocity.Dot(up)
+		if load <= 0 {
+			continue
+		}
+
+		// Tires roll along the heading of the wheel on the ground
+		heading := mgl64.QuatRotate(state.Steer, up).Rotate(b.rotation.Col(2))
+		forward := heading.Sub(hit.normal.Mul(heading.Dot(hit.normal)))
+		if forward.Len() < 1e-9 {
+			continue
+		}
+		forward = forward.Normalize()
+		side := hit.normal.Cross(forward)
+		forwardSpeed, sideSpeed := velocity.Dot(forward), velocity.Dot(side)
+
+		// Sliding is stopped by the share of the mass of the vehicle on the wheel
+		share := b.Mass / float64(grounded)
+		lateral := -sideSpeed * share / deltaTime
+
+		var longitudinal float64
+		if wheel.Driven {
+			longitudinal = v.throttle * v.EngineTorque / wheel.Radius / float64(driven)
+		}
+
+		// Brakes and rolling resistance slow the wheel down without ever
+		// pushing it backwards
+		resistance := v.brake*v.BrakeTorque/wheel.Radius + v.RollingResistance*load
+		stop := math.Abs(forwardSpeed) * share / deltaTime
+		longitudinal -= math.Copysign(math.Min(resistance, stop), forwardSpeed)
+
+		// The tire grips within an ellipse given by its load and frictions
+		if grip := math.Hypot(longitudinal/(v.LongitudinalFriction*load), lateral/(v.LateralFriction*load)); grip > 1 {
+			longitudinal /= grip
+			lateral /= grip
+		}
+
+		v.hits[i].contact = contact
+		v.hits[i].impulse = up.Mul(load).Add(forward.Mul(longitudinal)).Add(side.Mul(lateral)).Mul(deltaTime)
+	}
+
+	for _, hit := range v.hits {
+		if hit.impulse == (mgl64.Vec3{}) {
+			continue
+		}
+
+		// Tire forces push at the height given by the roll influence, so hard
+		// turns lean the body rather than roll it over
+		r := hit.contact.Sub(b.Position)
+		r = r.Sub(up.Mul(r.Dot(up) * (1 - v.RollInfluence)))
+		b.applyImpulse(hit.impulse, r)
+		if hit.ground.InverseMass != 0 {
+			p.wake(hit.ground)
+			hit.ground.applyImpulse(hit.impulse.Mul(-1), hit.contact.Sub(hit.ground.Position))
+		}
+	}
+}
+
+// castWheel casts the suspension ray of a wheel against the bodies the vehicle
+// collides with and the ground
+func (p *Physics) castWheel(b *body, origin, direction mgl64.Vec3, reach float64) wheelHit {
+	hit := wheelHit{distance: math.Inf(1)}
+	for _, h := range p.Raycast(origin, direction, reach, b.collisionMask(), true, b.pid) {
+		if other := p.entities[h.PID]; other != nil && b.interacts(other) {
+			hit = wheelHit{distance: h.Distance, normal: h.Normal, ground: other}
+			break
+		}
+	}
+
+	// Without terrain the floor plane at y=0 is not a body rays can hit
+	if p.terrain == nil && direction.Y() < 0 {
+		if t := origin.Y() / -direction.Y(); t >= 0 && t <= reach && t < hit.distance {
+			hit = wheelHit{distance: t, normal: mgl64.Vec3{0, 1, 0}, ground: p.ground}
+		}
+	}
+	return hit
+}
@@ -0,0 +1,69 @@
+package physics
+
+import (
+	"math"
+	"testing"
+
+	"github.com/anthdm/hollywood/actor"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// registerVehicle adds a car with the default controller at rest above the
+// floor, facing +Z
+func registerVehicle(p *Physics) *body {
+	pid := actor.NewPID("local", "car")
+	p.Register(pid, EntityRigidBody{
+		Position: mgl64.Vec3{0, 1.2, 0},
+		Scale:    mgl64.Vec3{2, 1, 4},
+		Mass:     10,
+		Material: &VehicleMaterial,
+		Vehicle:  &DefaultVehicleController,
+	})
+	return p.entities[pid]
+}
+
+func TestVehicleRestsOnItsSuspensionAndDrives(t *testing.T) {
+	p := newTestPhysics()
+	b := registerVehicle(p)
+	for i := 0; i < 256*2; i++ {
+		p.Step(FixedDeltaTime)
+	}
+
+	// The springs hold the body above the floor, level and still
+	if y := b.Position.Y(); y < 0.9 || y > 1.2 || len(p.stepContacts) != 0 {
+		t.Fatalf("vehicle should rest on its wheels, got y=%f and %d contacts", y, len(p.stepContacts))
+	}
+	for i, wheel := range b.vehicle.wheels {
+		if !wheel.Grounded || math.Abs(wheel.Position.Y()-0.4) > 0.01 {
+			t.Errorf("wheel %d should touch the floor, got %+v", i, wheel)
+		}
+	}
+
+	// Full throttle drives it forwards
+	p.apply(EventVehicleInput{PID: b.pid, Throttle: 1})
+	for i := 0; i < 256*2; i++ {
+		p.Step(FixedDeltaTime)
+	}
+	if b.Position.Z() < 5 || math.Abs(b.Position.X()) > 0.1 {
+		t.Errorf("vehicle should drive straight ahead, got %v", b.Position)
+	}
+
+	// Steering left turns it towards +X
+	p.apply(EventVehicleInput{PID: b.pid, Throttle: 1, Steering: 1})
+	for i := 0; i < 256; i++ {
+		p.Step(FixedDeltaTime)
+	}
+	heading := b.rotation.Col(2)
+	if heading.X() < 0.3 || b.Position.Y() < 0.9 {
+		t.Errorf("vehicle should turn left on its wheels, got heading %v at %v", heading, b.Position)
+	}
+
+	// Braking stops it
+	p.apply(EventVehicleInput{PID: b.pid, Brake: 1})
+	for i := 0; i < 256*3; i++ {
+		p.Step(FixedDeltaTime)
+	}
+	if speed := b.Velocity.Len(); speed > 0.1 {
+		t.Errorf("vehicle should brake to a stop, got speed %f", speed)
+	}
+}
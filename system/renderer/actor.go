@@ -5,6 +5,7 @@ import (
 	"otto/system/physics"
 
 	"github.com/anthdm/hollywood/actor"
+	"github.com/go-gl/mathgl/mgl64"
 )
 
 type Renderer interface {
@@ -13,7 +14,18 @@ type Renderer interface {
 
 type Render struct {
 	camera   system.Camera
-	entities map[*actor.PID]physics.EntityRigidBody
+	entities map[*actor.PID]*renderState
+	step     uint64  // Latest simulation step an entity was updated with
+	alpha    float64 // Interpolation factor published with that step
+}
+
+// renderState holds the transforms of an entity after and before the simulation
+// step it was last updated with
+type renderState struct {
+	current  physics.EntityRigidBody
+	previous physics.EntityRigidBody
+	tint     mgl64.Vec4
+	step     uint64
 }
 
 var _ actor.Receiver = (*Render)(nil)
@@ -27,19 +39,46 @@ func New() actor.Producer {
 func (r *Render) Receive(ctx *actor.Context) {
 	switch msg := ctx.Message().(type) {
 	case actor.Initialized:
-		r.entities = make(map[*actor.PID]physics.EntityRigidBody)
+		r.entities = make(map[*actor.PID]*renderState)
 		r.camera = system.Camera{}
 	case EventEntityRegister:
-		r.entities[msg.PID] = msg.EntityRigidBody
+		r.entities[msg.PID] = &renderState{current: msg.EntityRigidBody, previous: msg.EntityRigidBody, tint: msg.Tint}
 	case EventEntityRenderUpdate:
-		r.entities[msg.PID] = msg.EntityRigidBody
+		state, ok := r.entities[msg.PID]
+		if !ok {
+			state = &renderState{current: msg.EntityRigidBody}
+			r.entities[msg.PID] = state
+		}
+		state.previous, state.current, state.step = msg.Previous, msg.EntityRigidBody, msg.Step
+		state.tint = msg.Tint
+
+		// Transforms of the same step all carry the same interpolation factor
+		if msg.Step >= r.step {
+			r.step, r.alpha = msg.Step, msg.Alpha
+		}
 	case EventUpdateCamera:
 		r.camera = msg.Camera
 	case RequestEntities:
 		entities := make([]physics.EntityRigidBody, 0, len(r.entities))
-		for pid := range r.entities {
-			entities = append(entities, r.entities[pid])
+		previous := make([]physics.EntityRigidBody, 0, len(r.entities))
+		tints := make([]mgl64.Vec4, 0, len(r.entities))
+		for _, state := range r.entities {
+			entities = append(entities, state.current)
+			tints = append(tints, state.tint)
+
+			// Entities left out of the latest step did not move during it
+			if state.step == r.step {
+				previous = append(previous, state.previous)
+			} else {
+				previous = append(previous, state.current)
+			}
 		}
-		ctx.Respond(EntitiesResponse{Entities: entities, Camera: r.camera})
+		ctx.Respond(EntitiesResponse{
+			Entities: entities,
+			Previous: previous,
+			Tints:    tints,
+			Alpha:    r.alpha,
+			Camera:   r.camera,
+		})
 	}
 }
@@ -5,21 +5,39 @@ import (
 	"otto/system/physics"
 
 	"github.com/anthdm/hollywood/actor"
+	"github.com/go-gl/mathgl/mgl64"
 )
 
 type EventEntityRegister struct {
 	PID             *actor.PID
 	EntityRigidBody physics.EntityRigidBody
+	Tint            mgl64.Vec4 // Color the model is drawn with, zero draws it white
 }
 
+// EventEntityRenderUpdate carries the transform of an entity after a physics
+// step and the one before that step, with the step and the interpolation factor
+// they were published with
 type EventEntityRenderUpdate struct {
 	PID             *actor.PID
 	EntityRigidBody physics.EntityRigidBody
+	Previous        physics.EntityRigidBody
+	Tint            mgl64.Vec4
+	Step            uint64
+	Alpha           float64
 }
 
 type RequestEntities struct{}
+
+// EntitiesResponse holds the transforms of every entity after and before the
+// latest physics step.
+// Previous and Tints are aligned with Entities by index, and Alpha is how far
+// rendering should blend from Previous towards Entities. Entities that did not
+// move in the latest step have the same transform in both.
 type EntitiesResponse struct {
 	Entities []physics.EntityRigidBody
+	Previous []physics.EntityRigidBody
+	Tints    []mgl64.Vec4
+	Alpha    float64
 	Camera   system.Camera
 }
 
@@ -0,0 +1,59 @@
+package util
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Frustum is the volume seen by a camera, bounded by six planes facing
+// inwards: left, right, bottom, top, near and far. Every plane holds its
+// normal in XYZ and its distance from the origin in W.
+type Frustum [6]mgl32.Vec4
+
+// NewFrustum extracts the planes of the frustum of an OpenGL view-projection
+// matrix, a point being inside when its clip space coordinates are all
+// within -w and w
+func NewFrustum(viewProjection mgl32.Mat4) Frustum {
+	x, y, z, w := viewProjection.Row(0), viewProjection.Row(1), viewProjection.Row(2), viewProjection.Row(3)
+	frustum := Frustum{
+		w.Add(x), w.Sub(x), // Left, right
+		w.Add(y), w.Sub(y), // Bottom, top
+		w.Add(z), w.Sub(z), // Near, far
+	}
+
+	// Normalized planes give true distances, so boxes can be compared to them
+	for i, plane := range frustum {
+		if length := plane.Vec3().Len(); length > 0 {
+			frustum[i] = plane.Mul(1 / length)
+		}
+	}
+	return frustum
+}
+
+// IntersectsBox reports whether an oriented box is at least partly inside the
+// frustum. The box is given by its center, the rotation of its axes and its
+// half extents along them. Boxes near a corner of the frustum may be reported
+// inside while being just out of view, which is fine for culling.
+func (f Frustum) IntersectsBox(center mgl32.Vec3, rotation mgl32.Mat4, halfExtents mgl32.Vec3) bool {
+	axes := [3]mgl32.Vec3{
+		rotation.Col(0).Vec3().Mul(halfExtents.X()),
+		rotation.Col(1).Vec3().Mul(halfExtents.Y()),
+		rotation.Col(2).Vec3().Mul(halfExtents.Z()),
+	}
+
+	for _, plane := range f {
+		normal := plane.Vec3()
+
+		// The box is outside when even its corner furthest along the normal is
+		// behind the plane
+		radius := float32(0)
+		for _, axis := range axes {
+			radius += float32(math.Abs(float64(normal.Dot(axis))))
+		}
+		if normal.Dot(center)+plane.W() < -radius {
+			return false
+		}
+	}
+	return true
+}
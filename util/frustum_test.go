@@ -0,0 +1,66 @@
+package util
+
+import (
+	"math"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// testFrustum is a square view of 90 degrees looking down -Z from the origin,
+// from 1 to 100 units away
+func testFrustum() Frustum {
+	return NewFrustum(mgl32.Perspective(mgl32.DegToRad(90), 1, 1, 100))
+}
+
+func TestNewFrustumExtractsNormalizedPlanes(t *testing.T) {
+	diagonal := float32(1 / math.Sqrt2)
+	frustum := testFrustum()
+	for i, tc := range []struct {
+		name  string
+		plane mgl32.Vec4
+	}{
+		{name: "left", plane: mgl32.Vec4{diagonal, 0, -diagonal, 0}},
+		{name: "right", plane: mgl32.Vec4{-diagonal, 0, -diagonal, 0}},
+		{name: "bottom", plane: mgl32.Vec4{0, diagonal, -diagonal, 0}},
+		{name: "top", plane: mgl32.Vec4{0, -diagonal, -diagonal, 0}},
+		{name: "near", plane: mgl32.Vec4{0, 0, -1, -1}},
+		{name: "far", plane: mgl32.Vec4{0, 0, 1, 100}},
+	} {
+		if !frustum[i].ApproxEqualThreshold(tc.plane, 1e-4) {
+			t.Errorf("%s plane should be %v, got %v", tc.name, tc.plane, frustum[i])
+		}
+	}
+}
+
+func TestFrustumIntersectsBox(t *testing.T) {
+	frustum := testFrustum()
+	turned := mgl32.HomogRotate3DZ(mgl32.DegToRad(90))
+	for _, tc := range []struct {
+		name     string
+		center   mgl32.Vec3
+		rotation mgl32.Mat4
+		half     mgl32.Vec3
+		inside   bool
+	}{
+		{name: "inside", center: mgl32.Vec3{0, 0, -10}, half: mgl32.Vec3{1, 1, 1}, inside: true},
+		{name: "left", center: mgl32.Vec3{-30, 0, -10}, half: mgl32.Vec3{1, 1, 1}},
+		{name: "above", center: mgl32.Vec3{0, 30, -10}, half: mgl32.Vec3{1, 1, 1}},
+		{name: "behind", center: mgl32.Vec3{0, 0, 10}, half: mgl32.Vec3{1, 1, 1}},
+		{name: "beyond far", center: mgl32.Vec3{0, 0, -200}, half: mgl32.Vec3{1, 1, 1}},
+		{name: "straddling near", center: mgl32.Vec3{0, 0, -0.5}, half: mgl32.Vec3{1, 1, 1}, inside: true},
+		{name: "straddling left", center: mgl32.Vec3{-10, 0, -10}, half: mgl32.Vec3{1, 1, 1}, inside: true},
+		{name: "straddling far", center: mgl32.Vec3{0, 0, -100}, half: mgl32.Vec3{1, 1, 1}, inside: true},
+		{name: "long reaching in", center: mgl32.Vec3{-14, 0, -10}, half: mgl32.Vec3{5, 0.1, 0.1}, inside: true},
+		{name: "long turned away", center: mgl32.Vec3{-14, 0, -10}, rotation: turned, half: mgl32.Vec3{5, 0.1, 0.1}},
+		{name: "turned reaching in", center: mgl32.Vec3{-14, 0, -10}, rotation: turned, half: mgl32.Vec3{0.1, 5, 0.1}, inside: true},
+	} {
+		rotation := tc.rotation
+		if rotation == (mgl32.Mat4{}) {
+			rotation = mgl32.Ident4()
+		}
+		if got := frustum.IntersectsBox(tc.center, rotation, tc.half); got != tc.inside {
+			t.Errorf("%s: box at %v should be inside=%v, got %v", tc.name, tc.center, tc.inside, got)
+		}
+	}
+}
@@ -66,3 +66,13 @@ func Vec3UpVector(vec mgl64.Vec3) mgl64.Vec3 {
 func Vec64ToVec32(v mgl64.Vec3) mgl32.Vec3 {
 	return mgl32.Vec3{float32(v.X()), float32(v.Y()), float32(v.Z())}
 }
+
+// Quat64ToMat4 converts an mgl64.Quat to an mgl32 rotation matrix
+func Quat64ToMat4(q mgl64.Quat) mgl32.Mat4 {
+	m := q.Mat4()
+	var result mgl32.Mat4
+	for i := range m {
+		result[i] = float32(m[i])
+	}
+	return result
+}
@@ -0,0 +1,40 @@
+package util
+
+import "sync"
+
+// Subscribers hands every published value to a set of buffered channels
+// without ever blocking the publisher. Subscribers falling behind lose their
+// oldest values, so the latest one is always delivered. Values can be
+// published and subscribed to from any goroutine.
+type Subscribers[T any] struct {
+	mu       sync.Mutex
+	channels []chan T
+}
+
+// Subscribe returns a new channel buffering up to size values
+func (s *Subscribers[T]) Subscribe(size int) <-chan T {
+	channel := make(chan T, size)
+	s.mu.Lock()
+	s.channels = append(s.channels, channel)
+	s.mu.Unlock()
+	return channel
+}
+
+// Publish sends a value to every subscriber, dropping the oldest value of those
+// whose channel is full. Only Publish sends on the channels and it holds the
+// lock while doing so, so there is room once a value has been taken out.
+func (s *Subscribers[T]) Publish(value T) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, channel := range s.channels {
+		select {
+		case channel <- value:
+		default:
+			select {
+			case <-channel:
+			default:
+			}
+			channel <- value
+		}
+	}
+}
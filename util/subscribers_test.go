@@ -0,0 +1,59 @@
+package util
+
+import "testing"
+
+func TestSubscribersKeepTheLatestValues(t *testing.T) {
+	var s Subscribers[int]
+	slow, fast := s.Subscribe(4), s.Subscribe(4)
+
+	// The fast subscriber keeps up, the slow one falls behind by more than
+	// its buffer and only loses the oldest values
+	var received []int
+	for i := 1; i <= 10; i++ {
+		s.Publish(i)
+		received = append(received, <-fast)
+	}
+	if len(received) != 10 || received[9] != 10 {
+		t.Errorf("a subscriber keeping up should get every value, got %v", received)
+	}
+
+	var latest []int
+	for len(slow) > 0 {
+		latest = append(latest, <-slow)
+	}
+	if want := []int{7, 8, 9, 10}; len(latest) != len(want) || latest[0] != want[0] || latest[3] != want[3] {
+		t.Errorf("a slow subscriber should keep the latest %v, got %v", want, latest)
+	}
+
+	// Publishing without subscribers or to an emptied channel never blocks
+	var none Subscribers[int]
+	none.Publish(1)
+	s.Publish(11)
+	if v := <-slow; v != 11 {
+		t.Errorf("expected the next value once caught up, got %d", v)
+	}
+}
+
+func TestSubscribersAcceptSubscriptionsWhilePublishing(t *testing.T) {
+	var s Subscribers[int]
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 1000; i++ {
+			s.Publish(i)
+		}
+	}()
+
+	// Subscribers joining while values are published still get the latest one
+	var channels []<-chan int
+	for i := 0; i < 10; i++ {
+		channels = append(channels, s.Subscribe(1))
+	}
+	<-done
+	s.Publish(1000)
+	for i, channel := range channels {
+		if v := <-channel; v != 1000 {
+			t.Errorf("subscriber %d should end with the last value, got %d", i, v)
+		}
+	}
+}
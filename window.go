@@ -2,6 +2,7 @@ package otto
 
 import (
 	"fmt"
+	"otto/util"
 	"time"
 
 	"github.com/AllenDang/cimgui-go/backend"
@@ -10,10 +11,27 @@ import (
 	"github.com/go-gl/gl/v4.1-core/gl"
 )
 
+// windowEventBuffer is how many events a subscriber can fall behind before
+// missing some
+const windowEventBuffer = 16
+
 type Window interface {
 	Run(func(deltaTime float64))
 	Width() int
 	Height() int
+	FramebufferSize() (width, height int)
+	Subscribe() <-chan WindowEvent
+}
+
+// WindowEvent is sent to the subscribers of a window
+type WindowEvent any
+
+// EventWindowResize is sent when the size of a window changes. The size is in
+// screen coordinates, the framebuffer size in pixels, which differ on high DPI
+// displays.
+type EventWindowResize struct {
+	Width, Height                       int
+	FramebufferWidth, FramebufferHeight int
 }
 
 type SDLWindow struct {
@@ -22,6 +40,11 @@ type SDLWindow struct {
 	width  int
 	height int
 
+	framebufferWidth  int
+	framebufferHeight int
+
+	events util.Subscribers[WindowEvent]
+
 	lastTime time.Time
 }
 
@@ -76,6 +99,7 @@ func NewSDLBackendWithOpenGL(width, height int, title string) (*SDLWindow, error
 		width:    width,
 		height:   height,
 	}
+	window.framebufferWidth, window.framebufferHeight = width, height
 
 	return window, nil
 }
@@ -88,6 +112,8 @@ func (w *SDLWindow) Run(f func(deltaTime float64)) {
 		// Ensure depth buffer is properly initialized
 		gl.ClearDepth(1.0)
 
+		w.updateSize()
+
 		currentTime := time.Now()
 		deltaTime := currentTime.Sub(w.lastTime).Seconds()
 		w.lastTime = currentTime
@@ -96,10 +122,55 @@ func (w *SDLWindow) Run(f func(deltaTime float64)) {
 	})
 }
 
+// updateSize follows the size of the window, which the SDL backend does not
+// report through its size callback. The backend resets the viewport to the
+// window size every frame, so it is set back to the framebuffer size, which is
+// larger on high DPI displays.
+func (w *SDLWindow) updateSize() {
+	width, height := w.DisplaySize()
+	scale := imgui.CurrentIO().DisplayFramebufferScale()
+	framebufferWidth := int(float32(width) * scale.X)
+	framebufferHeight := int(float32(height) * scale.Y)
+
+	// Minimized windows have no size, the last one is kept until they come back
+	if framebufferWidth <= 0 || framebufferHeight <= 0 {
+		return
+	}
+	gl.Viewport(0, 0, int32(framebufferWidth), int32(framebufferHeight))
+
+	if int(width) == w.width && int(height) == w.height &&
+		framebufferWidth == w.framebufferWidth && framebufferHeight == w.framebufferHeight {
+		return
+	}
+	w.width, w.height = int(width), int(height)
+	w.framebufferWidth, w.framebufferHeight = framebufferWidth, framebufferHeight
+
+	w.events.Publish(EventWindowResize{
+		Width:             w.width,
+		Height:            w.height,
+		FramebufferWidth:  framebufferWidth,
+		FramebufferHeight: framebufferHeight,
+	})
+}
+
+// Subscribe returns a channel receiving the events of the window. Subscribers
+// falling behind lose their oldest events rather than stalling the frame, so
+// the latest resize, which carries the whole size, is always delivered.
+func (w *SDLWindow) Subscribe() <-chan WindowEvent {
+	return w.events.Subscribe(windowEventBuffer)
+}
+
+// Width returns the width of the window in screen coordinates
 func (w *SDLWindow) Width() int {
 	return w.width
 }
 
+// Height returns the height of the window in screen coordinates
 func (w *SDLWindow) Height() int {
 	return w.height
 }
+
+// FramebufferSize returns the size of the framebuffer of the window in pixels
+func (w *SDLWindow) FramebufferSize() (width, height int) {
+	return w.framebufferWidth, w.framebufferHeight
+}